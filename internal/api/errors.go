@@ -18,6 +18,7 @@ const (
 	ErrorTypeExternal     ErrorType = "external_service"
 	ErrorTypeInternal     ErrorType = "internal"
 	ErrorTypeUnauthorized ErrorType = "unauthorized"
+	ErrorTypePrecondition ErrorType = "precondition_failed"
 )
 
 // ErrorResponse стандартный формат ошибки API
@@ -115,6 +116,20 @@ var (
 		nil,
 	)
 
+	ErrUnauthorized = NewError(
+		ErrorTypeUnauthorized,
+		http.StatusUnauthorized,
+		"Admin token required",
+		nil,
+	)
+
+	ErrPreconditionFailed = NewError(
+		ErrorTypePrecondition,
+		http.StatusPreconditionFailed,
+		"Resource was modified, reload it and retry",
+		nil,
+	)
+
 	ErrExternalAPI = NewError(
 		ErrorTypeExternal,
 		http.StatusBadGateway,
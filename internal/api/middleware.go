@@ -1,6 +1,10 @@
 package api
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"people-service/internal/audit"
+
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 )
@@ -24,3 +28,35 @@ func ErrorMiddleware() gin.HandlerFunc {
 		}
 	}
 }
+
+// Заголовки с ID запроса и автором изменения
+const (
+	RequestIDHeader = "X-Request-ID"
+	ActorHeader     = "X-Actor"
+)
+
+// AuditMiddleware берет ID запроса из X-Request-ID или генерирует новый,
+// возвращает его в ответе и кладет вместе с X-Actor в контекст запроса
+// для истории изменений
+func AuditMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" {
+			requestID = newRequestID()
+		}
+		c.Header(RequestIDHeader, requestID)
+
+		ctx := audit.WithMeta(c.Request.Context(), audit.Meta{
+			Actor:     c.GetHeader(ActorHeader),
+			RequestID: requestID,
+		})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
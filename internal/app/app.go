@@ -0,0 +1,222 @@
+// Package app собирает зависимости сервиса и HTTP-роутер
+package app
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"people-service/internal/api"
+	"people-service/internal/config"
+	"people-service/internal/db"
+	"people-service/internal/enrich"
+	"people-service/internal/models"
+	"people-service/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/redis/go-redis/v9"
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+	"gorm.io/gorm"
+)
+
+// shutdownTimeout сколько Run ждет завершения текущих запросов при остановке
+const shutdownTimeout = 10 * time.Second
+
+// App владеет конфигурацией, подключениями и зависимостями обработчиков
+type App struct {
+	cfg      *config.Config
+	db       *gorm.DB
+	redis    *redis.Client
+	enricher enrich.Provider
+	cassette io.Closer
+	people   repository.PersonRepository
+	validate *validator.Validate
+}
+
+// New создает приложение из готовых зависимостей; используется в тестах
+// с фейковым хранилищем и источником обогащения
+func New(cfg *config.Config, people repository.PersonRepository, enricher enrich.Provider) *App {
+	return &App{
+		cfg:      cfg,
+		enricher: enricher,
+		people:   people,
+		validate: validator.New(),
+	}
+}
+
+// Bootstrap подключается к Redis и БД, применяет миграции и настраивает обогащение
+func Bootstrap(cfg *config.Config) (*App, error) {
+	identity, err := models.ParseIdentityRule(cfg.People.IdentityRule)
+	if err != nil {
+		return nil, err
+	}
+
+	// Инициализация Redis
+	redisClient, err := enrich.NewRedisClient(cfg.Redis.Addr(), cfg.Redis.Password, cfg.Redis.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	// Источники обогащения
+	enricher, cassette, err := newEnricher(cfg.Enrich, enrich.NewRedisCache(redisClient))
+	if err != nil {
+		redisClient.Close()
+		return nil, err
+	}
+
+	// Подключение к БД
+	conn, err := OpenDatabase(cfg.DB)
+	if err != nil {
+		redisClient.Close()
+		if cassette != nil {
+			cassette.Close()
+		}
+		return nil, err
+	}
+
+	people := repository.NewGormRepository(conn, identity)
+	a := New(cfg, people, enricher)
+	a.db = conn
+	a.redis = redisClient
+	a.cassette = cassette
+
+	// Миграции
+	if err := migrate(conn); err != nil {
+		a.Close()
+		return nil, err
+	}
+	backfilled, err := people.BackfillNameKeys(context.Background())
+	if err != nil {
+		a.Close()
+		return nil, err
+	}
+	if backfilled > 0 {
+		log.Printf("Computed search keys for %d people", backfilled)
+	}
+	rekeyed, err := people.BackfillIdentityKeys(context.Background())
+	if err != nil {
+		a.Close()
+		return nil, err
+	}
+	if rekeyed > 0 {
+		log.Printf("Updated identity keys of %d people for rule %s", rekeyed, identity)
+	}
+	return a, nil
+}
+
+// OpenDatabase подключается к БД, повторяя попытки, пока база поднимается
+func OpenDatabase(cfg config.DBConfig) (*gorm.DB, error) {
+	var conn *gorm.DB
+	var err error
+	for i := 0; i < 5; i++ {
+		conn, err = db.InitDB(cfg)
+		if err == nil {
+			return conn, nil
+		}
+		log.Printf("DB connection attempt %d failed: %v", i+1, err)
+		time.Sleep(5 * time.Second)
+	}
+	return nil, err
+}
+
+func migrate(conn *gorm.DB) error {
+	sqlDB, err := conn.DB()
+	if err != nil {
+		return err
+	}
+	migrator, err := db.NewMigrator(sqlDB, conn.Dialector.Name())
+	if err != nil {
+		return err
+	}
+
+	applied, err := migrator.Up(context.Background())
+	if err != nil {
+		log.Printf("Migration error: %v", err)
+		return err
+	}
+	for _, m := range applied {
+		log.Printf("Applied migration %04d_%s", m.Version, m.Name)
+	}
+	return nil
+}
+
+// Router строит gin-роутер с обработчиками приложения
+func (a *App) Router() *gin.Engine {
+	r := gin.Default()
+
+	r.Use(api.ErrorMiddleware(), api.AuditMiddleware())
+
+	r.POST("/people", a.createPerson)
+	r.GET("/people", a.getPeople)
+	r.GET("/people/deleted", a.getDeletedPeople)
+	r.GET("/people/stats", a.getStats)
+	r.GET("/people/search", a.searchPeople)
+	r.GET("/people/by-external-id/:external_id", a.getPersonByExternalID)
+	r.GET("/people/duplicates", a.findDuplicates)
+	r.POST("/people/merge", a.mergePeople)
+	r.GET("/people/:id/merges", a.getMerges)
+	r.POST("/people/:id/restore", a.restorePerson)
+	r.GET("/people/:id/history", a.getHistory)
+	r.GET("/people/:id/history/:version", a.getVersion)
+	r.POST("/people/:id/history/:version/revert", a.revertPerson)
+	r.GET("/people/:id", a.getPerson)
+	r.PUT("/people/:id", a.updatePerson)
+	r.PATCH("/people/:id", a.patchPerson)
+	r.DELETE("/people/:id", a.deletePerson)
+	r.GET("/enrich/preview", a.previewEnrichment)
+	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+
+	return r
+}
+
+// Run запускает HTTP-сервер на порту из конфигурации и фоновую очистку
+// удаленных записей. По SIGINT или SIGTERM дожидается текущих запросов
+// и возвращает nil, чтобы вызывающий успел закрыть приложение
+func (a *App) Run() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go a.runRetention(ctx)
+
+	server := &http.Server{Addr: ":" + a.cfg.Server.Port, Handler: a.Router()}
+	errCh := make(chan error, 1)
+	go func() {
+		log.Printf("Server running on %s", server.Addr)
+		errCh <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Print("Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	return server.Shutdown(shutdownCtx)
+}
+
+// Close закрывает подключения к БД и Redis и кассету записи обогащения
+func (a *App) Close() error {
+	var errs []error
+	if a.cassette != nil {
+		errs = append(errs, a.cassette.Close())
+	}
+	if a.db != nil {
+		if sqlDB, err := a.db.DB(); err == nil {
+			errs = append(errs, sqlDB.Close())
+		}
+	}
+	if a.redis != nil {
+		errs = append(errs, a.redis.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,104 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"log"
+
+	"people-service/internal/config"
+	"people-service/internal/enrich"
+)
+
+// newEnricher собирает цепочку источников обогащения из конфигурации.
+// Возвращает также кассету записи, которую нужно закрыть при остановке
+// (nil, если запись выключена)
+func newEnricher(cfg config.EnrichConfig, cache enrich.Cache) (enrich.Provider, io.Closer, error) {
+	api := enrich.NewAPIProvider()
+	api.AgifyURL = cfg.AgifyURL
+	api.GenderizeURL = cfg.GenderizeURL
+	api.NationalizeURL = cfg.NationalizeURL
+	api.Client.Timeout = cfg.Timeout
+	api.Cache = cache
+	api.TTL = cfg.CacheTTL
+	api.NationalityBySurname = cfg.NationalityBySurname
+
+	cassette, err := initCassette(cfg, api)
+	if err != nil {
+		return nil, nil, err
+	}
+	provider, err := layerProviders(cfg, api)
+	if err != nil {
+		if cassette != nil {
+			cassette.Close()
+		}
+		return nil, nil, err
+	}
+	return provider, cassette, nil
+}
+
+// layerProviders добавляет к API набор данных и правила по фамилии и отчеству
+func layerProviders(cfg config.EnrichConfig, api *enrich.APIProvider) (enrich.Provider, error) {
+	var provider enrich.Provider = api
+	if cfg.DatasetPath != "" {
+		dataset, err := enrich.LoadDataset(cfg.DatasetPath)
+		if err != nil {
+			return nil, err
+		}
+
+		strategy := enrich.Strategy(cfg.Strategy)
+		switch strategy {
+		case enrich.StrategyWeightedVote, enrich.StrategyHighestConfidence:
+		default:
+			return nil, fmt.Errorf("unknown enrichment strategy %q", strategy)
+		}
+
+		provider = enrich.NewEnsemble(strategy,
+			enrich.Member{Provider: api, Weight: 1},
+			enrich.Member{Provider: dataset, Weight: cfg.DatasetWeight},
+		)
+	}
+
+	// Правила по фамилии и отчеству: off (по умолчанию), rules, providers
+	// или confidence
+	switch precedence := enrich.Precedence(cfg.NameRules); precedence {
+	case "", "off":
+	case enrich.PrecedenceRules, enrich.PrecedenceProviders, enrich.PrecedenceConfidence:
+		provider = enrich.WithRules(provider, enrich.NewNameRules(), precedence)
+	default:
+		return nil, fmt.Errorf("unknown name rules precedence %q", precedence)
+	}
+
+	return provider, nil
+}
+
+// initCassette включает запись или воспроизведение ответов внешних API
+// и возвращает открытую на запись кассету
+func initCassette(cfg config.EnrichConfig, api *enrich.APIProvider) (io.Closer, error) {
+	path := cfg.CassettePath
+
+	switch mode := enrich.CassetteMode(cfg.CassetteMode); mode {
+	case enrich.CassetteOff:
+	case enrich.CassetteRecord:
+		recorder, err := enrich.NewRecorder(path, api.Client.Transport)
+		if err != nil {
+			return nil, err
+		}
+		api.Client.Transport = recorder
+		// Ответы из кеша не дошли бы до кассеты
+		api.Cache = nil
+		log.Printf("Recording enrichment traffic to %s", path)
+		return recorder, nil
+	case enrich.CassetteReplay:
+		replayer, err := enrich.LoadCassette(path)
+		if err != nil {
+			return nil, err
+		}
+		api.Client.Transport = replayer
+		// Ответы из кеша обошли бы кассету, и воспроизведение зависело бы от Redis
+		api.Cache = nil
+		log.Printf("Replaying enrichment traffic from %s", path)
+	default:
+		return nil, fmt.Errorf("unknown cassette mode %q", mode)
+	}
+	return nil, nil
+}
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"hash/fnv"
+	"people-service/internal/models"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// etag возвращает сильный ETag полной записи, построенный из ее версии
+func etag(p *models.Person) string {
+	return `"` + strconv.Itoa(p.Version) + `"`
+}
+
+// viewETag возвращает ETag представления записи: к версии добавляется хеш
+// выбранных полей и вложений, чтобы разные представления одной версии
+// не считались одинаковыми
+func viewETag(p *models.Person, view models.ViewQuery) string {
+	key := view.Key()
+	if key == "" {
+		return etag(p)
+	}
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return `"` + strconv.Itoa(p.Version) + "-" + strconv.FormatUint(uint64(h.Sum32()), 16) + `"`
+}
+
+// ifMatch проверяет заголовок If-Match; без заголовка запрос разрешен.
+// Подходит ETag любого представления текущей версии, так как условие
+// защищает запись, а не полученный клиентом ответ
+func ifMatch(c *gin.Context, p *models.Person) bool {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		return true
+	}
+	return matchETag(header, etag(p), false, versionTag)
+}
+
+// ifNoneMatch сообщает, что клиент уже получил представление с ETag tag
+func ifNoneMatch(c *gin.Context, tag string) bool {
+	header := c.GetHeader("If-None-Match")
+	if header == "" {
+		return false
+	}
+	return matchETag(header, tag, true, nil)
+}
+
+// matchETag ищет tag в списке из заголовка If-Match или If-None-Match,
+// предварительно приводя кандидатов через normalize, если он задан.
+// Для If-None-Match допускается слабое сравнение (RFC 9110, 13.1.2)
+func matchETag(header, tag string, weak bool, normalize func(string) string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if normalize != nil {
+			candidate = normalize(candidate)
+		}
+		if candidate == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// versionTag отбрасывает из ETag представления хеш view, оставляя версию
+func versionTag(tag string) string {
+	if i := strings.IndexByte(tag, '-'); i > 0 && strings.HasSuffix(tag, `"`) {
+		return tag[:i] + `"`
+	}
+	return tag
+}
@@ -0,0 +1,624 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"people-service/internal/api"
+	"people-service/internal/enrich"
+	"people-service/internal/models"
+	"people-service/internal/patch"
+	"people-service/internal/repository"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Добавить человека
+// @Description Создает новую запись с обогащением данных
+// @Tags people
+// @Accept json
+// @Produce json
+// @Param input body models.Person true "Данные человека"
+// @Success 201 {object} models.Person
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 409 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /people [post]
+func (a *App) createPerson(c *gin.Context) {
+	var person models.Person
+	if err := c.ShouldBindJSON(&person); err != nil {
+		api.HandleError(c, api.NewError(
+			api.ErrorTypeValidation,
+			http.StatusBadRequest,
+			"Invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	if err := a.validate.Struct(person); err != nil {
+		api.HandleError(c, err)
+		return
+	}
+
+	if err := person.Enrich(a.enricher); err != nil {
+		api.HandleError(c, api.NewError(
+			api.ErrorTypeExternal,
+			http.StatusFailedDependency,
+			"Failed to enrich data",
+			err.Error(),
+		))
+		return
+	}
+
+	if err := a.people.Create(c.Request.Context(), &person); err != nil {
+		handleWriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, person)
+}
+
+// @Summary Получить список людей
+// @Description Возвращает список с возможностью фильтрации и пагинацией: по номеру страницы или по курсору из next_cursor/prev_cursor. Ссылки на соседние страницы передаются в заголовке Link
+// @Tags people
+// @Accept json
+// @Produce json
+// @Param name query string false "Фильтр по имени"
+// @Param surname query string false "Фильтр по фамилии"
+// @Param patronymic query string false "Фильтр по отчеству"
+// @Param match query string false "Сравнение имени, фамилии и отчества: translit — по латинской форме (Dmitriy находит Дмитрий), phonetic — еще и по звучанию (Dmitriy находит Dmitry)" Enums(contains, prefix, exact, translit, phonetic) default(contains)
+// @Param age query int false "Точный возраст"
+// @Param age_min query int false "Минимальный возраст"
+// @Param age_max query int false "Максимальный возраст"
+// @Param gender query []string false "Пол, несколько через запятую" collectionFormat(csv)
+// @Param nationality query []string false "Национальности, несколько через запятую" collectionFormat(csv)
+// @Param created_from query string false "Создан не раньше (RFC 3339)"
+// @Param created_to query string false "Создан раньше (RFC 3339)"
+// @Param updated_from query string false "Изменен не раньше (RFC 3339)"
+// @Param updated_to query string false "Изменен раньше (RFC 3339)"
+// @Param is_null query []string false "Незаполненные поля: patronymic, gender, nationality" collectionFormat(csv)
+// @Param sort query string false "Поля сортировки через запятую, минус — по убыванию: id, name, surname, patronymic, age, gender, nationality, created_at, updated_at" default(-created_at)
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Лимит записей, не больше PEOPLE_MAX_LIMIT" default(10)
+// @Param cursor query string false "Курсор из next_cursor или prev_cursor; заменяет page"
+// @Param skip_count query bool false "Не считать общее число записей"
+// @Param fields query []string false "Возвращаемые поля: id, name, surname, patronymic, age, gender, nationality, external_id, version, created_at, updated_at, deleted_at" collectionFormat(csv)
+// @Param expand query []string false "Вложения: enrichment — предсказания, сохраненные при создании записи, history — версии записи" collectionFormat(csv)
+// @Success 200 {object} models.PeopleListResponse
+// @Header 200 {string} Link "Ссылки rel=next и rel=prev"
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /people [get]
+func (a *App) getPeople(c *gin.Context) {
+	var filter models.PersonFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		api.HandleError(c, api.NewError(
+			api.ErrorTypeValidation,
+			http.StatusBadRequest,
+			"Invalid query parameters",
+			err.Error(),
+		))
+		return
+	}
+
+	filter.Normalize()
+	filter.Paginate(a.cfg.People.DefaultLimit, a.cfg.People.MaxLimit)
+	if err := a.validate.Struct(filter); err != nil {
+		api.HandleError(c, err)
+		return
+	}
+	view, ok := a.bindView(c)
+	if !ok {
+		return
+	}
+	sort, err := models.ParseSort(filter.Sort)
+	if err != nil {
+		api.HandleError(c, api.NewError(
+			api.ErrorTypeValidation,
+			http.StatusBadRequest,
+			"Invalid sort parameter",
+			err.Error(),
+		))
+		return
+	}
+	if filter.Cursor != "" {
+		if _, err := models.DecodeCursor(filter.Cursor, sort); err != nil {
+			api.HandleError(c, api.NewError(
+				api.ErrorTypeValidation,
+				http.StatusBadRequest,
+				"Invalid cursor",
+				"cursor is malformed or was issued for a different sort",
+			))
+			return
+		}
+	}
+
+	page, err := a.people.List(c.Request.Context(), filter)
+	if err != nil {
+		api.HandleError(c, api.ErrDBOperation)
+		return
+	}
+
+	response := models.PeopleListResponse{
+		Data:       page.People,
+		Limit:      filter.Limit,
+		HasNext:    page.NextCursor != "",
+		NextCursor: page.NextCursor,
+		PrevCursor: page.PrevCursor,
+	}
+	if page.Total >= 0 {
+		response.SetTotal(page.Total)
+	}
+	if filter.Cursor == "" {
+		response.Page = filter.Page
+	}
+
+	setLinkHeader(c, page)
+	if view.Empty() {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	views, err := a.personViews(c.Request.Context(), page.People, view)
+	if err != nil {
+		api.HandleError(c, err)
+		return
+	}
+	// Data внешней структуры скрывает полные записи встроенного ответа
+	c.JSON(http.StatusOK, struct {
+		models.PeopleListResponse
+		Data []models.PersonView `json:"data"`
+	}{response, views})
+}
+
+// @Summary Получить человека
+// @Description С заголовком If-None-Match отвечает 304, если запись не изменилась
+// @Tags people
+// @Accept json
+// @Produce json
+// @Param id path int true "ID человека"
+// @Param If-None-Match header string false "ETag, полученный ранее"
+// @Param fields query []string false "Возвращаемые поля: id, name, surname, patronymic, age, gender, nationality, external_id, version, created_at, updated_at, deleted_at" collectionFormat(csv)
+// @Param expand query []string false "Вложения: enrichment — предсказания, сохраненные при создании записи, history — версии записи" collectionFormat(csv)
+// @Success 200 {object} models.Person
+// @Header 200 {string} ETag "Версия записи и выбранные fields и expand"
+// @Success 304 "Запись не изменилась"
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 404 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /people/{id} [get]
+func (a *App) getPerson(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		api.HandleError(c, api.NewError(
+			api.ErrorTypeValidation,
+			http.StatusBadRequest,
+			"Invalid ID format",
+			nil,
+		))
+		return
+	}
+
+	view, ok := a.bindView(c)
+	if !ok {
+		return
+	}
+
+	person, err := a.people.Get(c.Request.Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			api.HandleError(c, api.ErrNotFound)
+		} else {
+			api.HandleError(c, api.ErrDBOperation)
+		}
+		return
+	}
+
+	a.writePerson(c, person, view)
+}
+
+// @Summary Получить человека по внешнему ID
+// @Description Находит запись по external_id, заданному клиентом. Поддерживает те же If-None-Match, fields и expand, что и получение по ID
+// @Tags people
+// @Accept json
+// @Produce json
+// @Param external_id path string true "Ключ записи во внешней системе"
+// @Param If-None-Match header string false "ETag, полученный ранее"
+// @Param fields query []string false "Возвращаемые поля: id, name, surname, patronymic, age, gender, nationality, external_id, version, created_at, updated_at, deleted_at" collectionFormat(csv)
+// @Param expand query []string false "Вложения: enrichment — предсказания, сохраненные при создании записи, history — версии записи" collectionFormat(csv)
+// @Success 200 {object} models.Person
+// @Header 200 {string} ETag "Версия записи и выбранные fields и expand"
+// @Success 304 "Запись не изменилась"
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 404 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /people/by-external-id/{external_id} [get]
+func (a *App) getPersonByExternalID(c *gin.Context) {
+	view, ok := a.bindView(c)
+	if !ok {
+		return
+	}
+
+	person, err := a.people.GetByExternalID(c.Request.Context(), c.Param("external_id"))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			api.HandleError(c, api.ErrNotFound)
+		} else {
+			api.HandleError(c, api.ErrDBOperation)
+		}
+		return
+	}
+
+	a.writePerson(c, person, view)
+}
+
+// writePerson отвечает записью с ETag или 304, если клиент ее уже видел
+func (a *App) writePerson(c *gin.Context, person *models.Person, view models.ViewQuery) {
+	tag := viewETag(person, view)
+	c.Header("ETag", tag)
+	if ifNoneMatch(c, tag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	if view.Empty() {
+		c.JSON(http.StatusOK, person)
+		return
+	}
+
+	views, err := a.personViews(c.Request.Context(), []models.Person{*person}, view)
+	if err != nil {
+		api.HandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, views[0])
+}
+
+// @Summary Заменить данные человека
+// @Description Заменяет все задаваемые клиентом поля: пропущенные поля очищаются. С заголовком If-Match обновляет запись, только если ее ETag не изменился
+// @Tags people
+// @Accept json
+// @Produce json
+// @Param id path int true "ID человека"
+// @Param If-Match header string false "ETag, полученный при чтении записи"
+// @Param input body models.PersonFields true "Новые данные"
+// @Success 200 {object} models.Person
+// @Header 200 {string} ETag "Версия записи"
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 404 {object} api.ErrorResponse
+// @Failure 409 {object} api.ErrorResponse
+// @Failure 412 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /people/{id} [put]
+func (a *App) updatePerson(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		api.HandleError(c, api.NewError(
+			api.ErrorTypeValidation,
+			http.StatusBadRequest,
+			"Invalid ID format",
+			nil,
+		))
+		return
+	}
+
+	person, err := a.people.Get(c.Request.Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			api.HandleError(c, api.ErrNotFound)
+		} else {
+			api.HandleError(c, api.ErrDBOperation)
+		}
+		return
+	}
+
+	if !ifMatch(c, person) {
+		api.HandleError(c, api.ErrPreconditionFailed)
+		return
+	}
+
+	var fields models.PersonFields
+	if err := c.ShouldBindJSON(&fields); err != nil {
+		api.HandleError(c, api.NewError(
+			api.ErrorTypeValidation,
+			http.StatusBadRequest,
+			"Invalid request body",
+			err.Error(),
+		))
+		return
+	}
+	person.SetFields(fields)
+
+	if err := a.validate.Struct(person); err != nil {
+		api.HandleError(c, err)
+		return
+	}
+
+	if err := a.people.Update(c.Request.Context(), person); err != nil {
+		handleWriteError(c, err)
+		return
+	}
+
+	c.Header("ETag", etag(person))
+	c.JSON(http.StatusOK, person)
+}
+
+// @Summary Частично обновить данные человека
+// @Description Принимает JSON Merge Patch (application/merge-patch+json или application/json) либо JSON Patch (application/json-patch+json), применяемый к полям models.PersonFields. С заголовком If-Match обновляет запись, только если ее ETag не изменился
+// @Tags people
+// @Accept json
+// @Accept application/merge-patch+json
+// @Accept application/json-patch+json
+// @Produce json
+// @Param id path int true "ID человека"
+// @Param If-Match header string false "ETag, полученный при чтении записи"
+// @Param input body object true "Merge patch или список операций JSON Patch"
+// @Success 200 {object} models.Person
+// @Header 200 {string} ETag "Версия записи"
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 404 {object} api.ErrorResponse
+// @Failure 409 {object} api.ErrorResponse
+// @Failure 412 {object} api.ErrorResponse
+// @Failure 415 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /people/{id} [patch]
+func (a *App) patchPerson(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		api.HandleError(c, api.NewError(
+			api.ErrorTypeValidation,
+			http.StatusBadRequest,
+			"Invalid ID format",
+			nil,
+		))
+		return
+	}
+
+	person, err := a.people.Get(c.Request.Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			api.HandleError(c, api.ErrNotFound)
+		} else {
+			api.HandleError(c, api.ErrDBOperation)
+		}
+		return
+	}
+
+	if !ifMatch(c, person) {
+		api.HandleError(c, api.ErrPreconditionFailed)
+		return
+	}
+
+	body, err := c.GetRawData()
+	if err != nil {
+		api.HandleError(c, api.NewError(
+			api.ErrorTypeValidation,
+			http.StatusBadRequest,
+			"Invalid request body",
+			err.Error(),
+		))
+		return
+	}
+	doc, err := json.Marshal(person.Fields())
+	if err != nil {
+		api.HandleError(c, err)
+		return
+	}
+
+	var patched []byte
+	switch c.ContentType() {
+	case patch.JSONPatchContentType:
+		patched, err = patch.JSONPatch(doc, body)
+	case patch.MergePatchContentType, gin.MIMEJSON:
+		patched, err = patch.MergePatch(doc, body)
+	default:
+		api.HandleError(c, api.NewError(
+			api.ErrorTypeValidation,
+			http.StatusUnsupportedMediaType,
+			"Unsupported patch content type",
+			[]string{patch.MergePatchContentType, patch.JSONPatchContentType, gin.MIMEJSON},
+		))
+		return
+	}
+	if err != nil {
+		if errors.Is(err, patch.ErrTestFailed) {
+			api.HandleError(c, api.NewError(
+				api.ErrorTypeConflict,
+				http.StatusConflict,
+				"Patch test operation failed",
+				err.Error(),
+			))
+		} else {
+			api.HandleError(c, api.NewError(
+				api.ErrorTypeValidation,
+				http.StatusBadRequest,
+				"Invalid patch",
+				err.Error(),
+			))
+		}
+		return
+	}
+
+	// Патч не может добавить поля, которых нет в PersonFields
+	var fields models.PersonFields
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&fields); err != nil {
+		api.HandleError(c, api.NewError(
+			api.ErrorTypeValidation,
+			http.StatusBadRequest,
+			"Invalid patch result",
+			err.Error(),
+		))
+		return
+	}
+	person.SetFields(fields)
+
+	if err := a.validate.Struct(person); err != nil {
+		api.HandleError(c, err)
+		return
+	}
+
+	if err := a.people.Update(c.Request.Context(), person); err != nil {
+		handleWriteError(c, err)
+		return
+	}
+
+	c.Header("ETag", etag(person))
+	c.JSON(http.StatusOK, person)
+}
+
+// @Summary Удалить человека
+// @Description Помечает запись удаленной; с hard=true удаляет ее физически (нужен заголовок X-Admin-Token)
+// @Tags people
+// @Accept json
+// @Produce json
+// @Param id path int true "ID человека"
+// @Param hard query bool false "Удалить без возможности восстановления"
+// @Param X-Admin-Token header string false "Токен администратора для hard=true"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 401 {object} api.ErrorResponse
+// @Failure 404 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /people/{id} [delete]
+func (a *App) deletePerson(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		api.HandleError(c, api.NewError(
+			api.ErrorTypeValidation,
+			http.StatusBadRequest,
+			"Invalid ID format",
+			nil,
+		))
+		return
+	}
+
+	hard, err := strconv.ParseBool(c.DefaultQuery("hard", "false"))
+	if err != nil {
+		api.HandleError(c, api.NewError(
+			api.ErrorTypeValidation,
+			http.StatusBadRequest,
+			"Invalid hard flag",
+			err.Error(),
+		))
+		return
+	}
+
+	if hard {
+		if !a.isAdmin(c) {
+			api.HandleError(c, api.ErrUnauthorized)
+			return
+		}
+		err = a.people.Purge(c.Request.Context(), uint(id))
+	} else {
+		err = a.people.Delete(c.Request.Context(), uint(id))
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			api.HandleError(c, api.ErrNotFound)
+		} else {
+			api.HandleError(c, api.ErrDBOperation)
+		}
+		return
+	}
+
+	if hard {
+		c.JSON(http.StatusOK, gin.H{"message": "Person purged successfully"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Person deleted successfully"})
+}
+
+// @Summary Предпросмотр обогащения
+// @Description Возвращает предсказанные возраст, пол и национальность с вероятностями и статусом кеша, ничего не сохраняя
+// @Tags enrich
+// @Accept json
+// @Produce json
+// @Param name query string true "Имя"
+// @Param surname query string false "Фамилия"
+// @Param patronymic query string false "Отчество"
+// @Success 200 {object} models.EnrichmentPreviewResponse
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 424 {object} api.ErrorResponse
+// @Router /enrich/preview [get]
+func (a *App) previewEnrichment(c *gin.Context) {
+	var query models.EnrichmentPreviewQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		api.HandleError(c, api.NewError(
+			api.ErrorTypeValidation,
+			http.StatusBadRequest,
+			"Invalid query parameters",
+			err.Error(),
+		))
+		return
+	}
+
+	if err := a.validate.Struct(query); err != nil {
+		api.HandleError(c, err)
+		return
+	}
+
+	q := enrich.Query{Name: query.Name, Surname: query.Surname, Patronymic: query.Patronymic}
+	result, err := enrich.Preview(a.enricher, q)
+	if err != nil {
+		api.HandleError(c, api.NewError(
+			api.ErrorTypeExternal,
+			http.StatusFailedDependency,
+			"Failed to enrich data",
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.EnrichmentPreviewResponse{Query: q, Result: result})
+}
+
+// setLinkHeader добавляет ссылки на соседние страницы по курсорам,
+// сохраняя остальные параметры запроса
+func setLinkHeader(c *gin.Context, page *repository.Page) {
+	var links []string
+	for _, link := range []struct{ rel, cursor string }{
+		{"next", page.NextCursor},
+		{"prev", page.PrevCursor},
+	} {
+		if link.cursor == "" {
+			continue
+		}
+		u := *c.Request.URL
+		query := u.Query()
+		query.Del("page")
+		query.Set("cursor", link.cursor)
+		u.RawQuery = query.Encode()
+		links = append(links, fmt.Sprintf(`<%s>; rel="%s"`, u.RequestURI(), link.rel))
+	}
+	if len(links) > 0 {
+		c.Header("Link", strings.Join(links, ", "))
+	}
+}
+
+// handleWriteError отвечает 409 с ID конфликтующей записи при нарушении
+// уникальности, 412 при конкурентном изменении и 500 при прочих ошибках
+// хранилища
+func handleWriteError(c *gin.Context, err error) {
+	var dup *repository.DuplicateError
+	switch {
+	case errors.As(err, &dup):
+		api.HandleError(c, api.NewError(
+			api.ErrorTypeConflict,
+			http.StatusConflict,
+			"Person already exists",
+			gin.H{"id": dup.ExistingID, "field": dup.Field},
+		))
+	case errors.Is(err, repository.ErrNotFound):
+		api.HandleError(c, api.ErrNotFound)
+	case errors.Is(err, repository.ErrVersionConflict):
+		api.HandleError(c, api.ErrPreconditionFailed)
+	default:
+		api.HandleError(c, api.ErrDBOperation)
+	}
+}
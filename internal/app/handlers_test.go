@@ -0,0 +1,561 @@
+package app_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"people-service/internal/app"
+	"people-service/internal/config"
+	"people-service/internal/dedupe"
+	"people-service/internal/enrich"
+	"people-service/internal/models"
+	"people-service/internal/repository"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newTestRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	enricher := enrich.NewDataset("test", []enrich.DatasetEntry{
+		{Name: "Dmitriy", Age: 42, Gender: "male", GenderProbability: 0.99, Nationality: "RU", NationalityProbability: 0.7},
+	})
+	return app.New(&config.Config{}, repository.NewMemoryRepository(models.IdentityFullName), enricher).Router()
+}
+
+func do(r http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestPeopleHandlers(t *testing.T) {
+	r := newTestRouter(t)
+
+	w := do(r, http.MethodPost, "/people", `{"name": "Dmitriy", "surname": "Ushakov"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("POST /people status = %d, body %s", w.Code, w.Body)
+	}
+	var created models.Person
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+	if created.Age != 42 || created.Gender != "male" || created.Nationality != "RU" {
+		t.Errorf("created person not enriched: %+v", created)
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		wantStatus int
+	}{
+		{"invalid body", http.MethodPost, "/people", `{"name": "D"}`, http.StatusBadRequest},
+		{"duplicate", http.MethodPost, "/people", `{"name": "dmitriy", "surname": "USHAKOV"}`, http.StatusConflict},
+		{"list", http.MethodGet, "/people?name=dmit", "", http.StatusOK},
+		{"list rich filter", http.MethodGet, "/people?surname=Ush&match=prefix&age_min=18&gender=male,female&is_null=patronymic&created_from=2020-01-01T00:00:00Z", "", http.StatusOK},
+		{"list sorted", http.MethodGet, "/people?sort=surname,-age", "", http.StatusOK},
+		{"list unknown sort field", http.MethodGet, "/people?sort=height", "", http.StatusBadRequest},
+		{"list invalid match", http.MethodGet, "/people?name=d&match=fuzzy", "", http.StatusBadRequest},
+		{"list invalid gender", http.MethodGet, "/people?gender=male,robot", "", http.StatusBadRequest},
+		{"list invalid is_null", http.MethodGet, "/people?is_null=name", "", http.StatusBadRequest},
+		{"list invalid date", http.MethodGet, "/people?created_from=yesterday", "", http.StatusBadRequest},
+		{"update", http.MethodPut, "/people/1", `{"name": "Dmitriy", "surname": "Ushakov", "age": 43}`, http.StatusOK},
+		{"update missing", http.MethodPut, "/people/99", `{"name": "Anna", "surname": "Ivanova"}`, http.StatusNotFound},
+		{"update invalid id", http.MethodPut, "/people/abc", `{}`, http.StatusBadRequest},
+		{"hard delete without token", http.MethodDelete, "/people/1?hard=true", "", http.StatusUnauthorized},
+		{"delete invalid hard flag", http.MethodDelete, "/people/1?hard=maybe", "", http.StatusBadRequest},
+		{"delete", http.MethodDelete, "/people/1", "", http.StatusOK},
+		{"delete again", http.MethodDelete, "/people/1", "", http.StatusNotFound},
+		{"deleted list", http.MethodGet, "/people/deleted", "", http.StatusOK},
+		{"deleted list translit", http.MethodGet, "/people/deleted?name=Дмитрий&match=translit", "", http.StatusOK},
+		{"restore", http.MethodPost, "/people/1/restore", "", http.StatusOK},
+		{"restore live", http.MethodPost, "/people/1/restore", "", http.StatusNotFound},
+		{"duplicates", http.MethodGet, "/people/duplicates?threshold=0.5", "", http.StatusOK},
+		{"duplicates invalid threshold", http.MethodGet, "/people/duplicates?threshold=2", "", http.StatusBadRequest},
+		{"merge missing", http.MethodPost, "/people/merge", `{"survivor_id": 1, "merged_ids": [99]}`, http.StatusNotFound},
+		{"merge into itself", http.MethodPost, "/people/merge", `{"survivor_id": 2, "merged_ids": [2]}`, http.StatusBadRequest},
+		{"preview", http.MethodGet, "/enrich/preview?name=Dmitriy&surname=Ushakov", "", http.StatusOK},
+		{"preview without name", http.MethodGet, "/enrich/preview", "", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := do(r, tt.method, tt.target, tt.body)
+			if w.Code != tt.wantStatus {
+				t.Errorf("%s %s status = %d, want %d, body %s", tt.method, tt.target, w.Code, tt.wantStatus, w.Body)
+			}
+		})
+	}
+}
+
+func TestMergeHandler(t *testing.T) {
+	r := newTestRouter(t)
+	for _, body := range []string{
+		`{"name": "Dmitriy", "surname": "Ushakov"}`,
+		`{"name": "Dmitry", "surname": "Ushakov", "patronymic": "Olegovich"}`,
+	} {
+		if w := do(r, http.MethodPost, "/people", body); w.Code != http.StatusCreated {
+			t.Fatalf("POST /people status = %d, body %s", w.Code, w.Body)
+		}
+	}
+
+	w := do(r, http.MethodGet, "/people/duplicates", "")
+	var clusters dedupe.ClusterPage
+	if err := json.Unmarshal(w.Body.Bytes(), &clusters); err != nil || len(clusters.Data) != 1 || len(clusters.Data[0].People) != 2 {
+		t.Fatalf("GET /people/duplicates = %s", w.Body)
+	}
+	if clusters.Page != 1 || clusters.HasNext {
+		t.Errorf("GET /people/duplicates page = %d, has_next = %v", clusters.Page, clusters.HasNext)
+	}
+
+	w = do(r, http.MethodPost, "/people/merge", `{"survivor_id": 1, "merged_ids": [2], "fields": {"patronymic": 2}}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("POST /people/merge status = %d, body %s", w.Code, w.Body)
+	}
+	var merged models.MergeResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &merged); err != nil {
+		t.Fatal(err)
+	}
+	if merged.Survivor.Patronymic == nil || *merged.Survivor.Patronymic != "Olegovich" {
+		t.Errorf("survivor patronymic = %v, want Olegovich", merged.Survivor.Patronymic)
+	}
+
+	if w := do(r, http.MethodGet, "/people/1/merges", ""); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"merged_id":2`) {
+		t.Errorf("GET /people/1/merges = %d %s", w.Code, w.Body)
+	}
+}
+
+func TestHardDelete(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{Server: config.ServerConfig{AdminToken: "secret"}}
+	r := app.New(cfg, repository.NewMemoryRepository(models.IdentityFullName), enrich.NewDataset("test", nil)).Router()
+
+	if w := do(r, http.MethodPost, "/people", `{"name": "Anna", "surname": "Ivanova"}`); w.Code != http.StatusCreated {
+		t.Fatalf("POST /people status = %d, body %s", w.Code, w.Body)
+	}
+
+	req := httptest.NewRequest(http.MethodDelete, "/people/1?hard=true", nil)
+	req.Header.Set("X-Admin-Token", "wrong")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("hard delete with wrong token status = %d, want 401", w.Code)
+	}
+
+	req.Header.Set("X-Admin-Token", "secret")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("hard delete status = %d, body %s", w.Code, w.Body)
+	}
+
+	if w := do(r, http.MethodPost, "/people/1/restore", ""); w.Code != http.StatusNotFound {
+		t.Errorf("restore after hard delete status = %d, want 404", w.Code)
+	}
+}
+
+func TestHistoryHandlers(t *testing.T) {
+	r := newTestRouter(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/people", strings.NewReader(`{"name": "Dmitriy", "surname": "Ushakov"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Actor", "alice")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated || w.Header().Get("X-Request-ID") == "" {
+		t.Fatalf("POST /people status = %d, X-Request-ID %q", w.Code, w.Header().Get("X-Request-ID"))
+	}
+	requestID := w.Header().Get("X-Request-ID")
+
+	if w := do(r, http.MethodPut, "/people/1", `{"name": "Dmitriy", "surname": "Ushakov", "age": 43}`); w.Code != http.StatusOK {
+		t.Fatalf("PUT /people/1 status = %d, body %s", w.Code, w.Body)
+	}
+
+	w = do(r, http.MethodGet, "/people/1/history", "")
+	var versions []models.PersonVersion
+	if err := json.Unmarshal(w.Body.Bytes(), &versions); err != nil || len(versions) != 2 {
+		t.Fatalf("GET /people/1/history = %d %s", w.Code, w.Body)
+	}
+	if versions[0].Actor != "alice" || versions[0].RequestID != requestID {
+		t.Errorf("first version = %+v, want actor alice and request ID %s", versions[0], requestID)
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		wantStatus int
+	}{
+		{"version", http.MethodGet, "/people/1/history/1", http.StatusOK},
+		{"missing version", http.MethodGet, "/people/1/history/5", http.StatusNotFound},
+		{"invalid version", http.MethodGet, "/people/1/history/abc", http.StatusBadRequest},
+		{"missing person", http.MethodGet, "/people/9/history", http.StatusNotFound},
+		{"revert", http.MethodPost, "/people/1/history/1/revert", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := do(r, tt.method, tt.target, ""); w.Code != tt.wantStatus {
+				t.Errorf("%s %s status = %d, want %d, body %s", tt.method, tt.target, w.Code, tt.wantStatus, w.Body)
+			}
+		})
+	}
+}
+
+func TestETag(t *testing.T) {
+	r := newTestRouter(t)
+	if w := do(r, http.MethodPost, "/people", `{"name": "Dmitriy", "surname": "Ushakov"}`); w.Code != http.StatusCreated {
+		t.Fatalf("POST /people status = %d", w.Code)
+	}
+
+	withHeader := func(method, target, header, value, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(header, value)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(r, http.MethodGet, "/people/1", "")
+	tag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || tag != `"1"` {
+		t.Fatalf("GET /people/1 = %d, ETag %q", w.Code, tag)
+	}
+
+	if w := withHeader(http.MethodGet, "/people/1", "If-None-Match", tag, ""); w.Code != http.StatusNotModified {
+		t.Errorf("GET with matching If-None-Match status = %d, want 304", w.Code)
+	}
+
+	w = do(r, http.MethodGet, "/people/1?fields=name,id", "")
+	viewTag := w.Header().Get("ETag")
+	if viewTag == tag || viewTag == "" {
+		t.Fatalf("GET with fields ETag = %q, want different from %q", viewTag, tag)
+	}
+	if w := do(r, http.MethodGet, "/people/1?fields=id&fields=name", ""); w.Header().Get("ETag") != viewTag {
+		t.Errorf("GET with reordered fields ETag = %q, want %q", w.Header().Get("ETag"), viewTag)
+	}
+	if w := withHeader(http.MethodGet, "/people/1?fields=name,id", "If-None-Match", tag, ""); w.Code != http.StatusOK {
+		t.Errorf("GET with fields and full-record If-None-Match status = %d, want 200", w.Code)
+	}
+	if w := withHeader(http.MethodGet, "/people/1?fields=name,id", "If-None-Match", viewTag, ""); w.Code != http.StatusNotModified {
+		t.Errorf("GET with fields and matching If-None-Match status = %d, want 304", w.Code)
+	}
+
+	body := `{"name": "Dmitriy", "surname": "Ushakov", "age": 43}`
+	w = withHeader(http.MethodPut, "/people/1", "If-Match", viewTag, body)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != `"2"` {
+		t.Fatalf("PUT with current If-Match = %d, ETag %q", w.Code, w.Header().Get("ETag"))
+	}
+
+	if w := withHeader(http.MethodPut, "/people/1", "If-Match", tag, body); w.Code != http.StatusPreconditionFailed {
+		t.Errorf("PUT with stale If-Match status = %d, want 412", w.Code)
+	}
+	if w := withHeader(http.MethodGet, "/people/1", "If-None-Match", tag, ""); w.Code != http.StatusOK {
+		t.Errorf("GET with stale If-None-Match status = %d, want 200", w.Code)
+	}
+	if w := do(r, http.MethodGet, "/people/7", ""); w.Code != http.StatusNotFound {
+		t.Errorf("GET /people/7 status = %d, want 404", w.Code)
+	}
+}
+
+func TestPatchPerson(t *testing.T) {
+	r := newTestRouter(t)
+	if w := do(r, http.MethodPost, "/people", `{"name": "Dmitriy", "surname": "Ushakov", "patronymic": "Olegovich"}`); w.Code != http.StatusCreated {
+		t.Fatalf("POST /people status = %d", w.Code)
+	}
+
+	patchReq := func(contentType, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPatch, "/people/1", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := patchReq("application/merge-patch+json", `{"patronymic": null, "age": 0}`)
+	var person models.Person
+	if err := json.Unmarshal(w.Body.Bytes(), &person); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("merge patch = %d %s", w.Code, w.Body)
+	}
+	if person.Patronymic != nil || person.Age != 0 || person.Gender != "male" {
+		t.Errorf("merge patch result = %+v, want cleared patronymic and age, kept gender", person)
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantStatus  int
+	}{
+		{"json patch", "application/json-patch+json", `[{"op": "test", "path": "/age", "value": 0}, {"op": "replace", "path": "/age", "value": 44}]`, http.StatusOK},
+		{"json patch test fails", "application/json-patch+json", `[{"op": "test", "path": "/age", "value": 1}]`, http.StatusConflict},
+		{"unknown field", "application/json-patch+json", `[{"op": "add", "path": "/id", "value": 7}]`, http.StatusBadRequest},
+		{"missing path", "application/json-patch+json", `[{"op": "remove", "path": "/nickname"}]`, http.StatusBadRequest},
+		{"invalid result", "application/json", `{"name": "D"}`, http.StatusBadRequest},
+		{"wrong type", "application/json", `{"age": "old"}`, http.StatusBadRequest},
+		{"unsupported content type", "text/plain", `age=1`, http.StatusUnsupportedMediaType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := patchReq(tt.contentType, tt.body); w.Code != tt.wantStatus {
+				t.Errorf("PATCH status = %d, want %d, body %s", w.Code, tt.wantStatus, w.Body)
+			}
+		})
+	}
+
+	// PUT заменяет все поля: пропущенный пол очищается
+	w = do(r, http.MethodPut, "/people/1", `{"name": "Dmitriy", "surname": "Ushakov"}`)
+	if err := json.Unmarshal(w.Body.Bytes(), &person); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("PUT /people/1 = %d %s", w.Code, w.Body)
+	}
+	if person.Gender != "" || person.Age != 0 {
+		t.Errorf("PUT result = %+v, want omitted fields cleared", person)
+	}
+}
+
+func TestCursorPagination(t *testing.T) {
+	r := newTestRouter(t)
+	for _, name := range []string{"Anna", "Boris", "Pavel"} {
+		if w := do(r, http.MethodPost, "/people", `{"name": "`+name+`", "surname": "Ivanov"}`); w.Code != http.StatusCreated {
+			t.Fatalf("POST /people status = %d, body %s", w.Code, w.Body)
+		}
+	}
+
+	w := do(r, http.MethodGet, "/people?sort=name&limit=2&skip_count=true", "")
+	var first models.PeopleListResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &first); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("GET /people = %d %s", w.Code, w.Body)
+	}
+	if first.Total != nil || first.NextCursor == "" {
+		t.Errorf("first page total = %v, next cursor %q", first.Total, first.NextCursor)
+	}
+	link := w.Header().Get("Link")
+	if !strings.Contains(link, `rel="next"`) || !strings.Contains(link, "cursor="+first.NextCursor) {
+		t.Errorf("Link = %q", link)
+	}
+
+	w = do(r, http.MethodGet, "/people?sort=name&limit=2&cursor="+first.NextCursor, "")
+	var second models.PeopleListResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &second); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("GET next page = %d %s", w.Code, w.Body)
+	}
+	if len(second.Data) != 1 || second.Data[0].Name != "Pavel" || second.NextCursor != "" || second.PrevCursor == "" {
+		t.Errorf("second page = %+v", second)
+	}
+	if second.Total == nil || *second.Total != 3 {
+		t.Errorf("second page total = %v, want 3", second.Total)
+	}
+
+	for _, target := range []string{
+		"/people?cursor=garbage",
+		"/people?sort=-name&cursor=" + first.NextCursor,
+	} {
+		if w := do(r, http.MethodGet, target, ""); w.Code != http.StatusBadRequest {
+			t.Errorf("GET %s status = %d, want 400", target, w.Code)
+		}
+	}
+}
+
+func TestListPaginationLimits(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{People: config.PeopleConfig{DefaultLimit: 2, MaxLimit: 3}}
+	r := app.New(cfg, repository.NewMemoryRepository(models.IdentityFullName), enrich.NewDataset("test", nil)).Router()
+	for _, name := range []string{"Anna", "Boris", "Pavel", "Oleg", "Ivan"} {
+		if w := do(r, http.MethodPost, "/people", `{"name": "`+name+`", "surname": "Ivanov"}`); w.Code != http.StatusCreated {
+			t.Fatalf("POST /people status = %d, body %s", w.Code, w.Body)
+		}
+	}
+
+	tests := []struct {
+		target         string
+		wantPage       int
+		wantLimit      int
+		wantTotalPages int64
+		wantHasNext    bool
+	}{
+		{"/people", 1, 2, 3, true},
+		{"/people?limit=1000000", 1, 3, 2, true},
+		{"/people?page=2&limit=3", 2, 3, 2, false},
+		{"/people?page=-4&limit=5", 1, 3, 2, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			w := do(r, http.MethodGet, tt.target, "")
+			var resp models.PeopleListResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Page != tt.wantPage || resp.Limit != tt.wantLimit || resp.HasNext != tt.wantHasNext ||
+				resp.TotalPages == nil || *resp.TotalPages != tt.wantTotalPages {
+				t.Errorf("GET %s = %s", tt.target, w.Body)
+			}
+		})
+	}
+}
+
+func TestSearchHandler(t *testing.T) {
+	r := newTestRouter(t)
+	for _, body := range []string{
+		`{"name": "Dmitriy", "surname": "Ushakov"}`,
+		`{"name": "Dmitriy", "surname": "Petrov"}`,
+	} {
+		if w := do(r, http.MethodPost, "/people", body); w.Code != http.StatusCreated {
+			t.Fatalf("POST /people status = %d, body %s", w.Code, w.Body)
+		}
+	}
+
+	w := do(r, http.MethodGet, "/people/search?q=ushakov", "")
+	var resp models.SearchResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusOK || len(resp.Data) != 1 || resp.Data[0].Highlight != "Dmitriy <mark>Ushakov</mark>" ||
+		resp.Mode != models.SearchFullText || resp.Config != models.SearchConfigSimple {
+		t.Errorf("GET /people/search = %d %s", w.Code, w.Body)
+	}
+
+	for _, target := range []string{
+		"/people/search",
+		"/people/search?q=%20%20",
+		"/people/search?q=ushakov&mode=soundex",
+		"/people/search?q=ushakov&config=german",
+	} {
+		if w := do(r, http.MethodGet, target, ""); w.Code != http.StatusBadRequest {
+			t.Errorf("GET %s status = %d, want 400", target, w.Code)
+		}
+	}
+}
+
+func TestFieldsAndExpand(t *testing.T) {
+	r := newTestRouter(t)
+	if w := do(r, http.MethodPost, "/people", `{"name": "Dmitriy", "surname": "Ushakov"}`); w.Code != http.StatusCreated {
+		t.Fatalf("POST /people status = %d, body %s", w.Code, w.Body)
+	}
+
+	tests := []struct {
+		target   string
+		wantKeys []string
+	}{
+		{"/people/1?fields=id,name", []string{"ID", "name"}},
+		{"/people/1?fields=surname&expand=enrichment", []string{"surname", "enrichment"}},
+		{"/people?fields=name,AGE", []string{"name", "age"}},
+		{"/people?fields=name&expand=history", []string{"name", "history"}},
+		{"/people?fields=surname&fields=id&expand=history,enrichment", []string{"surname", "ID", "history", "enrichment"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			w := do(r, http.MethodGet, tt.target, "")
+			if w.Code != http.StatusOK {
+				t.Fatalf("GET %s status = %d, body %s", tt.target, w.Code, w.Body)
+			}
+
+			var object map[string]json.RawMessage
+			if strings.HasPrefix(tt.target, "/people?") {
+				var resp struct {
+					Data  []map[string]json.RawMessage `json:"data"`
+					Limit int                          `json:"limit"`
+				}
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || len(resp.Data) != 1 || resp.Limit == 0 {
+					t.Fatalf("GET %s = %s", tt.target, w.Body)
+				}
+				object = resp.Data[0]
+			} else if err := json.Unmarshal(w.Body.Bytes(), &object); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(object) != len(tt.wantKeys) {
+				t.Errorf("GET %s = %s, want keys %v", tt.target, w.Body, tt.wantKeys)
+			}
+			for _, key := range tt.wantKeys {
+				if _, ok := object[key]; !ok {
+					t.Errorf("GET %s = %s, missing %q", tt.target, w.Body, key)
+				}
+			}
+		})
+	}
+
+	w := do(r, http.MethodGet, "/people?fields=id&expand=enrichment,history", "")
+	var expanded struct {
+		Data []struct {
+			Enrichment enrich.Result          `json:"enrichment"`
+			History    []models.PersonVersion `json:"history"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &expanded); err != nil || len(expanded.Data) != 1 {
+		t.Fatalf("GET with expand = %s, %v", w.Body, err)
+	}
+	if got := expanded.Data[0]; got.Enrichment.Age.Value != "42" || got.Enrichment.Gender.Value != "male" || len(got.History) != 1 {
+		t.Errorf("GET with expand = %s, want stored enrichment and one version", w.Body)
+	}
+
+	for _, target := range []string{"/people?fields=password", "/people?expand=friends", "/people/1?expand=friends"} {
+		if w := do(r, http.MethodGet, target, ""); w.Code != http.StatusBadRequest {
+			t.Errorf("GET %s status = %d, want 400", target, w.Code)
+		}
+	}
+}
+
+func TestExternalIDHandlers(t *testing.T) {
+	r := newTestRouter(t)
+	if w := do(r, http.MethodPost, "/people", `{"name": "Dmitriy", "surname": "Ushakov", "external_id": "crm-1"}`); w.Code != http.StatusCreated {
+		t.Fatalf("POST /people status = %d, body %s", w.Code, w.Body)
+	}
+
+	tests := []struct {
+		name, method, target, body string
+		wantCode                   int
+	}{
+		{"by external id", http.MethodGet, "/people/by-external-id/crm-1", "", http.StatusOK},
+		{"by external id with fields", http.MethodGet, "/people/by-external-id/crm-1?fields=id,external_id", "", http.StatusOK},
+		{"unknown external id", http.MethodGet, "/people/by-external-id/crm-2", "", http.StatusNotFound},
+		{"unknown id", http.MethodGet, "/people/42", "", http.StatusNotFound},
+		{"invalid id", http.MethodGet, "/people/abc", "", http.StatusBadRequest},
+		{"taken external id", http.MethodPost, "/people", `{"name": "Dmitriy", "surname": "Petrov", "external_id": "crm-1"}`, http.StatusConflict},
+		{"empty external id", http.MethodPost, "/people", `{"name": "Dmitriy", "surname": "Petrov", "external_id": ""}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := do(r, tt.method, tt.target, tt.body); w.Code != tt.wantCode {
+				t.Errorf("%s %s status = %d, want %d, body %s", tt.method, tt.target, w.Code, tt.wantCode, w.Body)
+			}
+		})
+	}
+}
+
+func TestStatsHandler(t *testing.T) {
+	r := newTestRouter(t)
+	if w := do(r, http.MethodPost, "/people", `{"name": "Dmitriy", "surname": "Ushakov"}`); w.Code != http.StatusCreated {
+		t.Fatalf("POST /people status = %d, body %s", w.Code, w.Body)
+	}
+
+	w := do(r, http.MethodGet, "/people/stats?bucket_width=5&gender=male", "")
+	var stats models.PeopleStats
+	if err := json.Unmarshal(w.Body.Bytes(), &stats); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusOK || stats.Total != 1 || stats.BucketWidth != 5 ||
+		len(stats.AgeBuckets) != 1 || stats.AgeBuckets[0].From != 40 || stats.Age.Median == nil || *stats.Age.Median != 42 {
+		t.Errorf("GET /people/stats = %d %s", w.Code, w.Body)
+	}
+
+	w = do(r, http.MethodGet, "/people/stats", "")
+	if err := json.Unmarshal(w.Body.Bytes(), &stats); err != nil || stats.BucketWidth != models.DefaultBucketWidth {
+		t.Errorf("GET /people/stats = %d %s, want default bucket width", w.Code, w.Body)
+	}
+
+	for _, target := range []string{"/people/stats?bucket_width=-1", "/people/stats?gender=robot", "/people/stats?age_min=abc"} {
+		if w := do(r, http.MethodGet, target, ""); w.Code != http.StatusBadRequest {
+			t.Errorf("GET %s status = %d, want 400", target, w.Code)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package app
+
+import (
+	"errors"
+	"net/http"
+	"people-service/internal/api"
+	"people-service/internal/repository"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary История изменений человека
+// @Description Возвращает все версии записи, включая удаление, со снимком, изменениями, автором (X-Actor) и ID запроса. Номер версии совпадает с version записи и ее ETag
+// @Tags history
+// @Accept json
+// @Produce json
+// @Param id path int true "ID человека"
+// @Success 200 {array} models.PersonVersion
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 404 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /people/{id}/history [get]
+func (a *App) getHistory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		api.HandleError(c, api.NewError(
+			api.ErrorTypeValidation,
+			http.StatusBadRequest,
+			"Invalid ID format",
+			nil,
+		))
+		return
+	}
+
+	versions, err := a.people.History(c.Request.Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			api.HandleError(c, api.ErrNotFound)
+		} else {
+			api.HandleError(c, api.ErrDBOperation)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, versions)
+}
+
+// @Summary Версия записи
+// @Tags history
+// @Accept json
+// @Produce json
+// @Param id path int true "ID человека"
+// @Param version path int true "Номер версии, равный version записи"
+// @Success 200 {object} models.PersonVersion
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 404 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /people/{id}/history/{version} [get]
+func (a *App) getVersion(c *gin.Context) {
+	id, version, ok := versionParams(c)
+	if !ok {
+		return
+	}
+
+	v, err := a.people.Version(c.Request.Context(), id, version)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			api.HandleError(c, api.ErrNotFound)
+		} else {
+			api.HandleError(c, api.ErrDBOperation)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, v)
+}
+
+// @Summary Откатить запись к версии
+// @Description Возвращает полям записи значения из указанной версии; откат сам записывается в историю. Отвечает 409, если имя из версии занято другой записью, и 412, если запись изменилась во время отката
+// @Tags history
+// @Accept json
+// @Produce json
+// @Param id path int true "ID человека"
+// @Param version path int true "Номер версии, равный version записи"
+// @Success 200 {object} models.Person
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 404 {object} api.ErrorResponse
+// @Failure 409 {object} api.ErrorResponse
+// @Failure 412 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /people/{id}/history/{version}/revert [post]
+func (a *App) revertPerson(c *gin.Context) {
+	id, version, ok := versionParams(c)
+	if !ok {
+		return
+	}
+
+	person, err := a.people.Revert(c.Request.Context(), id, version)
+	if err != nil {
+		handleWriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, person)
+}
+
+// versionParams разбирает ID человека и номер версии из пути; при ошибке
+// отвечает 400 и возвращает ok == false
+func versionParams(c *gin.Context) (id uint, version int, ok bool) {
+	personID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		api.HandleError(c, api.NewError(
+			api.ErrorTypeValidation,
+			http.StatusBadRequest,
+			"Invalid ID format",
+			nil,
+		))
+		return 0, 0, false
+	}
+	version, err = strconv.Atoi(c.Param("version"))
+	if err != nil {
+		api.HandleError(c, api.NewError(
+			api.ErrorTypeValidation,
+			http.StatusBadRequest,
+			"Invalid version format",
+			nil,
+		))
+		return 0, 0, false
+	}
+	return uint(personID), version, true
+}
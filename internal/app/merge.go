@@ -0,0 +1,207 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"people-service/internal/api"
+	"people-service/internal/dedupe"
+	"people-service/internal/models"
+	"people-service/internal/repository"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Найти вероятные дубликаты
+// @Description Находит пары записей с похожими именем, фамилией и отчеством по триграммному сходству после транслитерации и группирует их. Пары выводятся постранично от самых похожих, группы строятся из пар страницы
+// @Tags people
+// @Accept json
+// @Produce json
+// @Param threshold query number false "Минимальная оценка сходства" default(0.6)
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Число пар на странице, не больше PEOPLE_MAX_LIMIT" default(10)
+// @Success 200 {object} dedupe.ClusterPage
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /people/duplicates [get]
+func (a *App) findDuplicates(c *gin.Context) {
+	var query models.DuplicatesQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		api.HandleError(c, api.NewError(
+			api.ErrorTypeValidation,
+			http.StatusBadRequest,
+			"Invalid query parameters",
+			err.Error(),
+		))
+		return
+	}
+
+	if err := a.validate.Struct(query); err != nil {
+		api.HandleError(c, err)
+		return
+	}
+
+	if query.Threshold == 0 {
+		query.Threshold = dedupe.DefaultThreshold
+	}
+	query.Paginate(a.cfg.People.DefaultLimit, a.cfg.People.MaxLimit)
+
+	ctx := c.Request.Context()
+	pairs, more, err := a.people.DuplicatePairs(ctx, query)
+	if err != nil {
+		api.HandleError(c, api.ErrDBOperation)
+		return
+	}
+
+	ids := make([]uint, 0, 2*len(pairs))
+	for _, pair := range pairs {
+		ids = append(ids, pair.A, pair.B)
+	}
+	people, err := a.people.GetMany(ctx, ids)
+	if err != nil {
+		api.HandleError(c, api.ErrDBOperation)
+		return
+	}
+
+	c.JSON(http.StatusOK, dedupe.ClusterPage{
+		Data:    dedupe.Clusters(pairs, people),
+		Page:    query.Page,
+		Limit:   query.Limit,
+		HasNext: more,
+	})
+}
+
+// @Summary Слить дубликаты
+// @Description Объединяет записи в одну: поля берутся из выбранных записей, остальные записи удаляются, исходные данные сохраняются в журнал. Если любая из записей изменилась во время слияния, отвечает 412
+// @Tags people
+// @Accept json
+// @Produce json
+// @Param input body models.MergeRequest true "Параметры слияния"
+// @Success 200 {object} models.MergeResponse
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 404 {object} api.ErrorResponse
+// @Failure 409 {object} api.ErrorResponse
+// @Failure 412 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /people/merge [post]
+func (a *App) mergePeople(c *gin.Context) {
+	var req models.MergeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		api.HandleError(c, api.NewError(
+			api.ErrorTypeValidation,
+			http.StatusBadRequest,
+			"Invalid request body",
+			err.Error(),
+		))
+		return
+	}
+
+	if err := a.validate.Struct(req); err != nil {
+		api.HandleError(c, err)
+		return
+	}
+
+	seen := map[uint]bool{req.SurvivorID: true}
+	for _, id := range req.MergedIDs {
+		if seen[id] {
+			api.HandleError(c, api.NewError(
+				api.ErrorTypeValidation,
+				http.StatusBadRequest,
+				"Merged IDs must be unique and differ from survivor ID",
+				gin.H{"id": id},
+			))
+			return
+		}
+		seen[id] = true
+	}
+
+	ctx := c.Request.Context()
+	survivor, err := a.people.Get(ctx, req.SurvivorID)
+	if err != nil {
+		handleWriteError(c, err)
+		return
+	}
+
+	sources := make(map[uint]models.Person, len(req.MergedIDs))
+	merged := make([]models.Person, 0, len(req.MergedIDs))
+	for _, id := range req.MergedIDs {
+		person, err := a.people.Get(ctx, id)
+		if err != nil {
+			handleWriteError(c, err)
+			return
+		}
+		sources[id] = *person
+		merged = append(merged, *person)
+	}
+
+	if err := dedupe.ApplyFields(survivor, sources, req.Fields); err != nil {
+		api.HandleError(c, api.NewError(
+			api.ErrorTypeValidation,
+			http.StatusBadRequest,
+			"Invalid field selection",
+			err.Error(),
+		))
+		return
+	}
+
+	if err := a.validate.Struct(survivor); err != nil {
+		api.HandleError(c, err)
+		return
+	}
+
+	fields, err := json.Marshal(req.Fields)
+	if err != nil {
+		api.HandleError(c, err)
+		return
+	}
+
+	merges, err := a.people.Merge(ctx, survivor, merged, models.RawJSON(fields))
+	if err != nil {
+		handleWriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MergeResponse{Survivor: *survivor, Merges: merges})
+}
+
+// @Summary Журнал слияний
+// @Description Возвращает записи, слитые в указанного человека, с их данными до слияния
+// @Tags people
+// @Accept json
+// @Produce json
+// @Param id path int true "ID человека"
+// @Success 200 {array} models.PersonMerge
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 404 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /people/{id}/merges [get]
+func (a *App) getMerges(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		api.HandleError(c, api.NewError(
+			api.ErrorTypeValidation,
+			http.StatusBadRequest,
+			"Invalid ID format",
+			nil,
+		))
+		return
+	}
+
+	if _, err := a.people.Get(c.Request.Context(), uint(id)); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			api.HandleError(c, api.ErrNotFound)
+		} else {
+			api.HandleError(c, api.ErrDBOperation)
+		}
+		return
+	}
+
+	merges, err := a.people.Merges(c.Request.Context(), uint(id))
+	if err != nil {
+		api.HandleError(c, api.ErrDBOperation)
+		return
+	}
+
+	c.JSON(http.StatusOK, merges)
+}
@@ -0,0 +1,54 @@
+package app
+
+import (
+	"net/http"
+
+	"people-service/internal/api"
+	"people-service/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Полнотекстовый поиск людей
+// @Description Ищет сразу по имени, фамилии и отчеству и возвращает записи от самых релевантных с подсветкой совпадений тегом <mark>. Режим fts ищет слова целиком (config=russian учитывает словоформы, только в Postgres), режим trigram допускает опечатки
+// @Tags people
+// @Accept json
+// @Produce json
+// @Param q query string true "Строка поиска"
+// @Param mode query string false "Режим поиска" Enums(fts, trigram) default(fts)
+// @Param config query string false "Конфигурация полнотекстового поиска" Enums(simple, russian) default(simple)
+// @Param limit query int false "Максимальное число результатов"
+// @Success 200 {object} models.SearchResponse
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /people/search [get]
+func (a *App) searchPeople(c *gin.Context) {
+	var query models.SearchQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		api.HandleError(c, api.NewError(
+			api.ErrorTypeValidation,
+			http.StatusBadRequest,
+			"Invalid query parameters",
+			err.Error(),
+		))
+		return
+	}
+
+	query.Normalize(a.cfg.People.DefaultLimit, a.cfg.People.MaxLimit)
+	if err := a.validate.Struct(query); err != nil {
+		api.HandleError(c, err)
+		return
+	}
+
+	results, err := a.people.Search(c.Request.Context(), query)
+	if err != nil {
+		api.HandleError(c, api.ErrDBOperation)
+		return
+	}
+
+	response := models.SearchResponse{Data: results, Mode: query.Mode, Limit: query.Limit}
+	if query.Mode == models.SearchFullText {
+		response.Config = query.Config
+	}
+	c.JSON(http.StatusOK, response)
+}
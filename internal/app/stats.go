@@ -0,0 +1,70 @@
+package app
+
+import (
+	"net/http"
+
+	"people-service/internal/api"
+	"people-service/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Статистика по людям
+// @Description Считает в БД распределения записей, подходящих под фильтр: по полу, национальности и возрастным группам, а также средний возраст и процентили. Записи с возрастом 0 (не определен) не входят в возрастные группы, среднее и процентили и считаются в age.unknown. Параметры пагинации и сортировки не учитываются
+// @Tags people
+// @Accept json
+// @Produce json
+// @Param bucket_width query int false "Ширина возрастной группы, лет" default(10)
+// @Param name query string false "Фильтр по имени"
+// @Param surname query string false "Фильтр по фамилии"
+// @Param patronymic query string false "Фильтр по отчеству"
+// @Param match query string false "Сравнение имени, фамилии и отчества" Enums(contains, prefix, exact, translit, phonetic) default(contains)
+// @Param age query int false "Точный возраст"
+// @Param age_min query int false "Минимальный возраст"
+// @Param age_max query int false "Максимальный возраст"
+// @Param gender query []string false "Пол, несколько через запятую" collectionFormat(csv)
+// @Param nationality query []string false "Национальности, несколько через запятую" collectionFormat(csv)
+// @Param created_from query string false "Создан не раньше (RFC 3339)"
+// @Param created_to query string false "Создан раньше (RFC 3339)"
+// @Param updated_from query string false "Изменен не раньше (RFC 3339)"
+// @Param updated_to query string false "Изменен раньше (RFC 3339)"
+// @Param is_null query []string false "Незаполненные поля: patronymic, gender, nationality" collectionFormat(csv)
+// @Success 200 {object} models.PeopleStats
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /people/stats [get]
+func (a *App) getStats(c *gin.Context) {
+	var filter models.PersonFilter
+	var query models.StatsQuery
+	for _, target := range []any{&filter, &query} {
+		if err := c.ShouldBindQuery(target); err != nil {
+			api.HandleError(c, api.NewError(
+				api.ErrorTypeValidation,
+				http.StatusBadRequest,
+				"Invalid query parameters",
+				err.Error(),
+			))
+			return
+		}
+	}
+
+	filter.Normalize()
+	for _, target := range []any{filter, query} {
+		if err := a.validate.Struct(target); err != nil {
+			api.HandleError(c, err)
+			return
+		}
+	}
+
+	width := query.BucketWidth
+	if width == 0 {
+		width = models.DefaultBucketWidth
+	}
+
+	stats, err := a.people.Stats(c.Request.Context(), filter, width)
+	if err != nil {
+		api.HandleError(c, api.ErrDBOperation)
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
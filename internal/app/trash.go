@@ -0,0 +1,142 @@
+package app
+
+import (
+	"context"
+	"crypto/subtle"
+	"log"
+	"net/http"
+	"people-service/internal/api"
+	"people-service/internal/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Список удаленных людей
+// @Description Возвращает помеченные удаленными записи, начиная с удаленных последними
+// @Tags people
+// @Accept json
+// @Produce json
+// @Param name query string false "Фильтр по имени"
+// @Param surname query string false "Фильтр по фамилии"
+// @Param patronymic query string false "Фильтр по отчеству"
+// @Param match query string false "Сравнение имени, фамилии и отчества" Enums(contains, prefix, exact, translit, phonetic) default(contains)
+// @Param age query int false "Точный возраст"
+// @Param age_min query int false "Минимальный возраст"
+// @Param age_max query int false "Максимальный возраст"
+// @Param gender query []string false "Пол, несколько через запятую" collectionFormat(csv)
+// @Param nationality query []string false "Национальности, несколько через запятую" collectionFormat(csv)
+// @Param created_from query string false "Создан не раньше (RFC 3339)"
+// @Param created_to query string false "Создан раньше (RFC 3339)"
+// @Param updated_from query string false "Изменен не раньше (RFC 3339)"
+// @Param updated_to query string false "Изменен раньше (RFC 3339)"
+// @Param is_null query []string false "Незаполненные поля: patronymic, gender, nationality" collectionFormat(csv)
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Лимит записей, не больше PEOPLE_MAX_LIMIT" default(10)
+// @Success 200 {object} models.PeopleListResponse
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /people/deleted [get]
+func (a *App) getDeletedPeople(c *gin.Context) {
+	var filter models.PersonFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		api.HandleError(c, api.NewError(
+			api.ErrorTypeValidation,
+			http.StatusBadRequest,
+			"Invalid query parameters",
+			err.Error(),
+		))
+		return
+	}
+
+	filter.Normalize()
+	filter.Paginate(a.cfg.People.DefaultLimit, a.cfg.People.MaxLimit)
+	if err := a.validate.Struct(filter); err != nil {
+		api.HandleError(c, err)
+		return
+	}
+
+	list, total, err := a.people.ListDeleted(c.Request.Context(), filter)
+	if err != nil {
+		api.HandleError(c, api.ErrDBOperation)
+		return
+	}
+
+	response := models.PeopleListResponse{
+		Data:    list,
+		Page:    filter.Page,
+		Limit:   filter.Limit,
+		HasNext: int64(filter.Page*filter.Limit) < total,
+	}
+	response.SetTotal(total)
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Восстановить удаленного человека
+// @Tags people
+// @Accept json
+// @Produce json
+// @Param id path int true "ID человека"
+// @Success 200 {object} models.Person
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 404 {object} api.ErrorResponse
+// @Failure 409 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /people/{id}/restore [post]
+func (a *App) restorePerson(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		api.HandleError(c, api.NewError(
+			api.ErrorTypeValidation,
+			http.StatusBadRequest,
+			"Invalid ID format",
+			nil,
+		))
+		return
+	}
+
+	person, err := a.people.Restore(c.Request.Context(), uint(id))
+	if err != nil {
+		handleWriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, person)
+}
+
+// isAdmin проверяет заголовок X-Admin-Token; без настроенного токена
+// административные операции запрещены
+func (a *App) isAdmin(c *gin.Context) bool {
+	token := a.cfg.Server.AdminToken
+	if token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(c.GetHeader("X-Admin-Token")), []byte(token)) == 1
+}
+
+// runRetention периодически физически удаляет записи, помеченные удаленными
+// дольше срока хранения, пока не отменен ctx
+func (a *App) runRetention(ctx context.Context) {
+	retention, interval := a.cfg.People.DeletedRetention, a.cfg.People.PurgeInterval
+	if retention <= 0 || interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		purged, err := a.people.PurgeDeleted(ctx, time.Now().Add(-retention))
+		if err != nil {
+			log.Printf("Purge of deleted people failed: %v", err)
+		} else if purged > 0 {
+			log.Printf("Purged %d deleted people", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"slices"
+
+	"people-service/internal/api"
+	"people-service/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bindView разбирает параметры fields и expand; при ошибке отвечает 400
+func (a *App) bindView(c *gin.Context) (models.ViewQuery, bool) {
+	var view models.ViewQuery
+	if err := c.ShouldBindQuery(&view); err != nil {
+		api.HandleError(c, api.NewError(
+			api.ErrorTypeValidation,
+			http.StatusBadRequest,
+			"Invalid query parameters",
+			err.Error(),
+		))
+		return view, false
+	}
+
+	view.Normalize()
+	if err := a.validate.Struct(view); err != nil {
+		api.HandleError(c, err)
+		return view, false
+	}
+	return view, true
+}
+
+// personViews строит представления people с полями и вложениями view.
+// Обогащение берется из сохраненных при создании предсказаний, история
+// всех людей загружается одним запросом; ошибки возвращаются как
+// api.ErrorResponse
+func (a *App) personViews(ctx context.Context, people []models.Person, view models.ViewQuery) ([]models.PersonView, error) {
+	var history map[uint][]models.PersonVersion
+	if slices.Contains(view.Expand, models.ExpandHistory) {
+		ids := make([]uint, len(people))
+		for i, person := range people {
+			ids[i] = person.ID
+		}
+		var err error
+		if history, err = a.people.HistoryMany(ctx, ids); err != nil {
+			return nil, api.ErrDBOperation
+		}
+	}
+
+	views := make([]models.PersonView, len(people))
+	for i, person := range people {
+		v, err := models.NewPersonView(person, view.Fields)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, expand := range view.Expand {
+			var value any
+			switch expand {
+			case models.ExpandEnrichment:
+				value = person.Enrichment
+			case models.ExpandHistory:
+				versions := history[person.ID]
+				if versions == nil {
+					versions = []models.PersonVersion{}
+				}
+				value = versions
+			}
+			if err := v.Set(expand, value); err != nil {
+				return nil, err
+			}
+		}
+		views[i] = v
+	}
+	return views, nil
+}
@@ -0,0 +1,23 @@
+// Package audit переносит сведения об авторе изменения через context
+package audit
+
+import "context"
+
+// Meta автор и запрос, в рамках которого произошло изменение
+type Meta struct {
+	Actor     string
+	RequestID string
+}
+
+type metaKey struct{}
+
+// WithMeta возвращает контекст с meta
+func WithMeta(ctx context.Context, meta Meta) context.Context {
+	return context.WithValue(ctx, metaKey{}, meta)
+}
+
+// FromContext возвращает Meta из контекста или пустую, если ее нет
+func FromContext(ctx context.Context) Meta {
+	meta, _ := ctx.Value(metaKey{}).(Meta)
+	return meta
+}
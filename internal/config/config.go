@@ -1,27 +1,98 @@
 package config
 
 import (
+	"fmt"
 	"log"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
 	DB     DBConfig
+	Redis  RedisConfig
+	Enrich EnrichConfig
+	People PeopleConfig
 	Server ServerConfig
 }
 
 type DBConfig struct {
+	// Driver postgres или sqlite
+	Driver string
+	// SQLitePath путь к файлу базы SQLite или :memory:
+	SQLitePath string
+
+	// URL строка подключения вида postgres://...; если задана, заменяет
+	// Host, Port, User, Password, Name и SSLMode
+	URL      string
 	Host     string
 	Port     string
 	User     string
 	Password string
 	Name     string
+	SSLMode  string
+
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	// StatementTimeout ограничивает запросы приложения; миграции
+	// выполняются без ограничения
+	StatementTimeout time.Duration
+}
+
+type RedisConfig struct {
+	Host     string
+	Port     string
+	Password string
+	DB       int
+}
+
+type EnrichConfig struct {
+	AgifyURL       string
+	GenderizeURL   string
+	NationalizeURL string
+	Timeout        time.Duration
+	CacheTTL       time.Duration
+
+	NationalityBySurname bool
+	// NameRules правила по фамилии и отчеству: off (по умолчанию, только
+	// предсказания по имени), rules, providers или confidence. Включение
+	// меняет результаты обогащения, поэтому задается явно
+	NameRules string
+
+	DatasetPath   string
+	DatasetWeight float64
+	Strategy      string
+
+	CassetteMode string
+	CassettePath string
+}
+
+type PeopleConfig struct {
+	// IdentityRule правило уникальности записей: none (по умолчанию) или
+	// full_name. full_name отклоняет тезок, поэтому включается явно
+	IdentityRule string
+	// DeletedRetention срок хранения удаленных записей до физического
+	// удаления; 0 отключает очистку
+	DeletedRetention time.Duration
+	// PurgeInterval период запуска очистки удаленных записей
+	PurgeInterval time.Duration
+	// DefaultLimit размер страницы списка, если limit не задан
+	DefaultLimit int
+	// MaxLimit наибольший размер страницы; больший limit уменьшается до него
+	MaxLimit int
 }
 
 type ServerConfig struct {
 	Port string
+	// AdminToken токен заголовка X-Admin-Token для административных операций;
+	// пустой токен запрещает их
+	AdminToken string
 }
 
 func Load() *Config {
@@ -31,21 +102,146 @@ func Load() *Config {
 
 	return &Config{
 		DB: DBConfig{
-			Host:     getEnv("DB_HOST", "db"),
-			Port:     getEnv("DB_PORT", "5432"),
-			User:     getEnv("DB_USER", "postgres"),
-			Password: getEnv("DB_PASSWORD", "postgres"),
-			Name:     getEnv("DB_NAME", "people_db"),
+			Driver:           getEnv("DB_DRIVER", "postgres"),
+			SQLitePath:       getEnv("DB_SQLITE_PATH", "people.db"),
+			URL:              getEnv("DATABASE_URL", ""),
+			Host:             getEnv("DB_HOST", "db"),
+			Port:             getEnv("DB_PORT", "5432"),
+			User:             getEnv("DB_USER", "postgres"),
+			Password:         getEnv("DB_PASSWORD", "postgres"),
+			Name:             getEnv("DB_NAME", "people_db"),
+			SSLMode:          getEnv("DB_SSLMODE", "disable"),
+			MaxOpenConns:     getEnvInt("DB_MAX_OPEN_CONNS", 25),
+			MaxIdleConns:     getEnvInt("DB_MAX_IDLE_CONNS", 5),
+			ConnMaxLifetime:  getEnvDuration("DB_CONN_MAX_LIFETIME", 30*time.Minute),
+			ConnMaxIdleTime:  getEnvDuration("DB_CONN_MAX_IDLE_TIME", 5*time.Minute),
+			StatementTimeout: getEnvDuration("DB_STATEMENT_TIMEOUT", 30*time.Second),
+		},
+		Redis: RedisConfig{
+			Host:     getEnv("REDIS_HOST", "redis"),
+			Port:     getEnv("REDIS_PORT", "6379"),
+			Password: getEnv("REDIS_PASSWORD", ""),
+			DB:       getEnvInt("REDIS_DB", 0),
+		},
+		Enrich: EnrichConfig{
+			AgifyURL:             getEnv("AGIFY_URL", "https://api.agify.io"),
+			GenderizeURL:         getEnv("GENDERIZE_URL", "https://api.genderize.io"),
+			NationalizeURL:       getEnv("NATIONALIZE_URL", "https://api.nationalize.io"),
+			Timeout:              getEnvDuration("ENRICH_TIMEOUT", 10*time.Second),
+			CacheTTL:             getEnvDuration("ENRICH_CACHE_TTL", 24*time.Hour),
+			NationalityBySurname: getEnvBool("ENRICH_NATIONALITY_BY_SURNAME", false),
+			NameRules:            getEnv("ENRICH_NAME_RULES", "off"),
+			DatasetPath:          getEnv("ENRICH_DATASET_PATH", ""),
+			DatasetWeight:        getEnvFloat("ENRICH_DATASET_WEIGHT", 1),
+			Strategy:             getEnv("ENRICH_STRATEGY", "weighted_vote"),
+			CassetteMode:         getEnv("ENRICH_CASSETTE_MODE", ""),
+			CassettePath:         getEnv("ENRICH_CASSETTE_PATH", "enrich_cassette.jsonl"),
+		},
+		People: PeopleConfig{
+			IdentityRule:     getEnv("PEOPLE_IDENTITY_RULE", "none"),
+			DeletedRetention: getEnvDuration("PEOPLE_DELETED_RETENTION", 30*24*time.Hour),
+			PurgeInterval:    getEnvDuration("PEOPLE_PURGE_INTERVAL", time.Hour),
+			DefaultLimit:     getEnvInt("PEOPLE_DEFAULT_LIMIT", 10),
+			MaxLimit:         getEnvInt("PEOPLE_MAX_LIMIT", 100),
 		},
 		Server: ServerConfig{
-			Port: getEnv("SERVER_PORT", "8080"),
+			Port:       getEnv("SERVER_PORT", "8080"),
+			AdminToken: getEnv("ADMIN_TOKEN", ""),
 		},
 	}
 }
 
+// DSN возвращает строку подключения к Postgres
+func (c DBConfig) DSN() string {
+	if c.URL != "" {
+		return c.urlWithTimeout()
+	}
+
+	var dsn strings.Builder
+	for _, kv := range []struct{ key, value string }{
+		{"host", c.Host},
+		{"port", c.Port},
+		{"user", c.User},
+		{"password", c.Password},
+		{"dbname", c.Name},
+		{"sslmode", c.SSLMode},
+	} {
+		if dsn.Len() > 0 {
+			dsn.WriteByte(' ')
+		}
+		dsn.WriteString(kv.key + "=" + quoteDSNValue(kv.value))
+	}
+	if c.StatementTimeout > 0 {
+		fmt.Fprintf(&dsn, " statement_timeout=%d", c.StatementTimeout.Milliseconds())
+	}
+	return dsn.String()
+}
+
+// quoteDSNValue заключает значение строки key=value в одинарные кавычки,
+// экранируя кавычки и обратные слеши, чтобы пробелы и ' в пароле
+// не ломали строку подключения
+func quoteDSNValue(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
+
+// urlWithTimeout добавляет statement_timeout в DATABASE_URL, если он там не указан
+func (c DBConfig) urlWithTimeout() string {
+	if c.StatementTimeout <= 0 {
+		return c.URL
+	}
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return c.URL
+	}
+	q := u.Query()
+	if q.Get("statement_timeout") == "" {
+		q.Set("statement_timeout", strconv.FormatInt(c.StatementTimeout.Milliseconds(), 10))
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// Addr возвращает адрес Redis в формате host:port
+func (c RedisConfig) Addr() string {
+	return c.Host + ":" + c.Port
+}
+
 func getEnv(key, defaultValue string) string {
 	if value, exists := os.LookupEnv(key); exists {
 		return value
 	}
 	return defaultValue
 }
+
+func getEnvInt(key string, defaultValue int) int {
+	return parseEnv(key, defaultValue, strconv.Atoi)
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	return parseEnv(key, defaultValue, func(s string) (float64, error) {
+		return strconv.ParseFloat(s, 64)
+	})
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	return parseEnv(key, defaultValue, strconv.ParseBool)
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	return parseEnv(key, defaultValue, time.ParseDuration)
+}
+
+// parseEnv разбирает переменную окружения; при ошибке пишет в лог
+// и возвращает значение по умолчанию
+func parseEnv[T any](key string, defaultValue T, parse func(string) (T, error)) T {
+	raw, exists := os.LookupEnv(key)
+	if !exists || raw == "" {
+		return defaultValue
+	}
+	value, err := parse(raw)
+	if err != nil {
+		log.Printf("Invalid value %q for %s, using default: %v", raw, key, err)
+		return defaultValue
+	}
+	return value
+}
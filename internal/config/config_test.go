@@ -0,0 +1,56 @@
+package config_test
+
+import (
+	"people-service/internal/config"
+	"testing"
+	"time"
+)
+
+func TestDBConfigDSN(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.DBConfig
+		want string
+	}{
+		{
+			name: "key value",
+			cfg: config.DBConfig{
+				Host: "db", Port: "5432", User: "u", Password: "p", Name: "people", SSLMode: "require",
+				StatementTimeout: 5 * time.Second,
+			},
+			want: "host='db' port='5432' user='u' password='p' dbname='people' sslmode='require' statement_timeout=5000",
+		},
+		{
+			name: "key value with special characters",
+			cfg: config.DBConfig{
+				Host: "db", Port: "5432", User: "u", Password: `p a's\w`, Name: "people", SSLMode: "disable",
+			},
+			want: `host='db' port='5432' user='u' password='p a\'s\\w' dbname='people' sslmode='disable'`,
+		},
+		{
+			name: "database url",
+			cfg: config.DBConfig{
+				URL:              "postgres://u:p@db:5432/people?sslmode=disable",
+				Host:             "ignored",
+				StatementTimeout: time.Second,
+			},
+			want: "postgres://u:p@db:5432/people?sslmode=disable&statement_timeout=1000",
+		},
+		{
+			name: "database url keeps explicit timeout",
+			cfg: config.DBConfig{
+				URL:              "postgres://db/people?statement_timeout=200",
+				StatementTimeout: time.Second,
+			},
+			want: "postgres://db/people?statement_timeout=200",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cfg.DSN(); got != tt.want {
+				t.Errorf("DSN() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
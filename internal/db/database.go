@@ -3,15 +3,51 @@ package db
 import (
 	"fmt"
 
+	"people-service/internal/config"
+
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-func InitDB() (*gorm.DB, error) {
-	dsn := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		"db", "5432", "postgres", "postgres", "people_db",
-	)
+// Поддерживаемые драйверы; совпадают с gorm.Dialector.Name()
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+func InitDB(cfg config.DBConfig) (*gorm.DB, error) {
+	var dialector gorm.Dialector
+	switch cfg.Driver {
+	case DriverPostgres:
+		dialector = postgres.Open(cfg.DSN())
+	case DriverSQLite:
+		dialector = sqlite.Open(sqliteDSN(cfg.SQLitePath))
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", cfg.Driver)
+	}
+
+	conn, err := gorm.Open(dialector, &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := conn.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	if cfg.Driver == DriverSQLite {
+		// Одно соединение: SQLite не допускает параллельной записи,
+		// а база :memory: существует только внутри своего соединения
+		sqlDB.SetMaxOpenConns(1)
+		sqlDB.SetMaxIdleConns(1)
+		sqlDB.SetConnMaxLifetime(0)
+		sqlDB.SetConnMaxIdleTime(0)
+	}
 
-	return gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	return conn, nil
 }
@@ -0,0 +1,68 @@
+package db
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestEmbeddedMigrations(t *testing.T) {
+	postgres, err := loadMigrations(migrationFiles, "migrations/postgres")
+	if err != nil {
+		t.Fatalf("loadMigrations(postgres) error = %v", err)
+	}
+	sqlite, err := loadMigrations(migrationFiles, "migrations/sqlite")
+	if err != nil {
+		t.Fatalf("loadMigrations(sqlite) error = %v", err)
+	}
+	if len(postgres) == 0 {
+		t.Fatal("no embedded migrations")
+	}
+	if len(postgres) != len(sqlite) {
+		t.Fatalf("postgres has %d migrations, sqlite has %d", len(postgres), len(sqlite))
+	}
+
+	for i, m := range postgres {
+		if m.Version != i+1 {
+			t.Errorf("migration %s has version %d, want %d", m.Name, m.Version, i+1)
+		}
+		if sqlite[i].Version != m.Version || sqlite[i].Name != m.Name {
+			t.Errorf("sqlite migration %04d_%s does not match postgres %04d_%s",
+				sqlite[i].Version, sqlite[i].Name, m.Version, m.Name)
+		}
+	}
+}
+
+func TestLoadMigrationsErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files fstest.MapFS
+	}{
+		{
+			name: "missing down",
+			files: fstest.MapFS{
+				"m/0001_init.up.sql": {Data: []byte("SELECT 1")},
+			},
+		},
+		{
+			name: "conflicting names",
+			files: fstest.MapFS{
+				"m/0001_init.up.sql":    {Data: []byte("SELECT 1")},
+				"m/0001_other.down.sql": {Data: []byte("SELECT 1")},
+			},
+		},
+		{
+			name: "bad file name",
+			files: fstest.MapFS{
+				"m/init.sql": {Data: []byte("SELECT 1")},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := loadMigrations(tt.files, "m"); err == nil {
+				t.Error("loadMigrations() error = nil, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+-- Схема совпадает с той, что создавал gorm AutoMigrate, поэтому
+-- существующие базы принимают миграцию без изменений
+CREATE TABLE IF NOT EXISTS people (
+    id          bigserial PRIMARY KEY,
+    created_at  timestamptz,
+    updated_at  timestamptz,
+    deleted_at  timestamptz,
+    name        text,
+    surname     text,
+    patronymic  text,
+    age         bigint,
+    gender      text,
+    nationality text
+);
+
+CREATE INDEX IF NOT EXISTS idx_people_deleted_at ON people (deleted_at);
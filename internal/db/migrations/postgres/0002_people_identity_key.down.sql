@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_people_identity_key;
+ALTER TABLE people DROP COLUMN IF EXISTS identity_key;
@@ -0,0 +1,7 @@
+ALTER TABLE people ADD COLUMN IF NOT EXISTS identity_key text;
+
+-- Ключи существующих записей заполняет сервис при запуске по правилу
+-- из PEOPLE_IDENTITY_RULE (GormRepository.BackfillIdentityKeys)
+CREATE UNIQUE INDEX IF NOT EXISTS idx_people_identity_key
+    ON people (identity_key)
+    WHERE deleted_at IS NULL;
@@ -0,0 +1 @@
+ALTER TABLE people ADD COLUMN version integer NOT NULL DEFAULT 1;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_people_full_name_trgm;
+DROP INDEX IF EXISTS idx_people_search_vector;
+ALTER TABLE people DROP COLUMN search_vector;
+ALTER TABLE people DROP COLUMN full_name;
@@ -0,0 +1,18 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Полное имя для триграммного поиска и подсветки совпадений
+ALTER TABLE people ADD COLUMN full_name text
+    GENERATED ALWAYS AS (coalesce(name, '') || ' ' || coalesce(surname, '') || coalesce(' ' || nullif(patronymic, ''), '')) STORED;
+
+-- Лексемы в конфигурациях simple и russian, чтобы запрос в любой из них
+-- находил запись; имя и фамилия весят больше отчества
+ALTER TABLE people ADD COLUMN search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple'::regconfig, coalesce(name, '') || ' ' || coalesce(surname, '')), 'A') ||
+        setweight(to_tsvector('simple'::regconfig, coalesce(patronymic, '')), 'B') ||
+        setweight(to_tsvector('russian'::regconfig, coalesce(name, '') || ' ' || coalesce(surname, '')), 'A') ||
+        setweight(to_tsvector('russian'::regconfig, coalesce(patronymic, '')), 'B')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_people_search_vector ON people USING gin (search_vector);
+CREATE INDEX IF NOT EXISTS idx_people_full_name_trgm ON people USING gin (full_name gin_trgm_ops);
@@ -0,0 +1,23 @@
+-- Ключи поиска по транслитерации и фонетике вычисляются сервисом при
+-- записи; существующие строки заполняются при запуске
+-- (GormRepository.BackfillNameKeys), пока ключи NULL
+
+ALTER TABLE people ADD COLUMN name_translit text;
+ALTER TABLE people ADD COLUMN name_metaphone text;
+ALTER TABLE people ADD COLUMN name_phonetic text;
+ALTER TABLE people ADD COLUMN surname_translit text;
+ALTER TABLE people ADD COLUMN surname_metaphone text;
+ALTER TABLE people ADD COLUMN surname_phonetic text;
+ALTER TABLE people ADD COLUMN patronymic_translit text;
+ALTER TABLE people ADD COLUMN patronymic_metaphone text;
+ALTER TABLE people ADD COLUMN patronymic_phonetic text;
+
+CREATE INDEX IF NOT EXISTS idx_people_name_translit ON people (name_translit);
+CREATE INDEX IF NOT EXISTS idx_people_name_metaphone ON people (name_metaphone);
+CREATE INDEX IF NOT EXISTS idx_people_name_phonetic ON people (name_phonetic);
+CREATE INDEX IF NOT EXISTS idx_people_surname_translit ON people (surname_translit);
+CREATE INDEX IF NOT EXISTS idx_people_surname_metaphone ON people (surname_metaphone);
+CREATE INDEX IF NOT EXISTS idx_people_surname_phonetic ON people (surname_phonetic);
+CREATE INDEX IF NOT EXISTS idx_people_patronymic_translit ON people (patronymic_translit);
+CREATE INDEX IF NOT EXISTS idx_people_patronymic_metaphone ON people (patronymic_metaphone);
+CREATE INDEX IF NOT EXISTS idx_people_patronymic_phonetic ON people (patronymic_phonetic);
@@ -0,0 +1 @@
+ALTER TABLE people ADD COLUMN enrichment text;
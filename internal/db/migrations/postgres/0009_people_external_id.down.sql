@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_people_external_id;
+ALTER TABLE people DROP COLUMN external_id;
@@ -0,0 +1,6 @@
+ALTER TABLE people ADD COLUMN external_id text;
+
+-- Ключ внешней системы уникален среди неудаленных записей, как identity_key
+CREATE UNIQUE INDEX IF NOT EXISTS idx_people_external_id
+    ON people (external_id)
+    WHERE deleted_at IS NULL AND external_id IS NOT NULL;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_people_surname_translit_trgm;
@@ -0,0 +1,4 @@
+-- Поиск дубликатов отбирает кандидатов оператором % по транслитерированной
+-- фамилии; без индекса это сравнение всех пар
+CREATE INDEX IF NOT EXISTS idx_people_surname_translit_trgm
+    ON people USING gin (surname_translit gin_trgm_ops);
@@ -0,0 +1,5 @@
+-- Записи о слитых людях, которых уже нет, не дали бы вернуть ссылку
+DELETE FROM person_merges WHERE merged_id NOT IN (SELECT id FROM people);
+
+ALTER TABLE person_merges
+    ADD CONSTRAINT person_merges_merged_id_fkey FOREIGN KEY (merged_id) REFERENCES people (id);
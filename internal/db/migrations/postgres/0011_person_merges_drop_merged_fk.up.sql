@@ -0,0 +1,3 @@
+-- Журнал слияний хранит снимок слитой записи, поэтому ссылка на нее не нужна
+-- и не должна мешать физически удалить слитую запись, сохранив журнал
+ALTER TABLE person_merges DROP CONSTRAINT IF EXISTS person_merges_merged_id_fkey;
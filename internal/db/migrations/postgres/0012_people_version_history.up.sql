@@ -0,0 +1,10 @@
+-- Номер версии в истории теперь равен people.version. Удаление раньше не
+-- увеличивало people.version, поэтому такие записи догоняют историю
+UPDATE people p
+SET version = v.version
+FROM (
+    SELECT person_id, MAX(version) AS version
+    FROM person_versions
+    GROUP BY person_id
+) v
+WHERE p.id = v.person_id AND p.version < v.version;
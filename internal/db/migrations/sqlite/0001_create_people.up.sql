@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS people (
+    id          integer PRIMARY KEY AUTOINCREMENT,
+    created_at  datetime,
+    updated_at  datetime,
+    deleted_at  datetime,
+    name        text,
+    surname     text,
+    patronymic  text,
+    age         integer,
+    gender      text,
+    nationality text
+);
+
+CREATE INDEX IF NOT EXISTS idx_people_deleted_at ON people (deleted_at);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS person_merges (
+    id          integer PRIMARY KEY AUTOINCREMENT,
+    survivor_id integer NOT NULL REFERENCES people (id),
+    merged_id   integer NOT NULL REFERENCES people (id),
+    snapshot    text NOT NULL,
+    fields      text NOT NULL,
+    created_at  datetime NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_person_merges_survivor_id ON person_merges (survivor_id);
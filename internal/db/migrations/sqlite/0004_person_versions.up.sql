@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS person_versions (
+    id          integer PRIMARY KEY AUTOINCREMENT,
+    person_id   integer NOT NULL REFERENCES people (id),
+    version     integer NOT NULL,
+    operation   text NOT NULL,
+    snapshot    text NOT NULL,
+    diff        text NOT NULL,
+    actor       text NOT NULL DEFAULT '',
+    request_id  text NOT NULL DEFAULT '',
+    created_at  datetime NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_person_versions_person_version ON person_versions (person_id, version);
@@ -0,0 +1,27 @@
+-- FTS5 без стемминга, как конфигурация simple в Postgres; индекс
+-- поддерживается триггерами, удаленные записи отсекаются при поиске
+CREATE VIRTUAL TABLE people_fts USING fts5(
+    name, surname, patronymic,
+    content = 'people',
+    content_rowid = 'id',
+    tokenize = 'unicode61 remove_diacritics 2'
+);
+
+INSERT INTO people_fts (people_fts) VALUES ('rebuild');
+
+CREATE TRIGGER people_fts_insert AFTER INSERT ON people BEGIN
+    INSERT INTO people_fts (rowid, name, surname, patronymic)
+    VALUES (new.id, new.name, new.surname, new.patronymic);
+END;
+
+CREATE TRIGGER people_fts_delete AFTER DELETE ON people BEGIN
+    INSERT INTO people_fts (people_fts, rowid, name, surname, patronymic)
+    VALUES ('delete', old.id, old.name, old.surname, old.patronymic);
+END;
+
+CREATE TRIGGER people_fts_update AFTER UPDATE OF name, surname, patronymic ON people BEGIN
+    INSERT INTO people_fts (people_fts, rowid, name, surname, patronymic)
+    VALUES ('delete', old.id, old.name, old.surname, old.patronymic);
+    INSERT INTO people_fts (rowid, name, surname, patronymic)
+    VALUES (new.id, new.name, new.surname, new.patronymic);
+END;
@@ -0,0 +1,19 @@
+DROP INDEX IF EXISTS idx_people_name_translit;
+DROP INDEX IF EXISTS idx_people_name_metaphone;
+DROP INDEX IF EXISTS idx_people_name_phonetic;
+DROP INDEX IF EXISTS idx_people_surname_translit;
+DROP INDEX IF EXISTS idx_people_surname_metaphone;
+DROP INDEX IF EXISTS idx_people_surname_phonetic;
+DROP INDEX IF EXISTS idx_people_patronymic_translit;
+DROP INDEX IF EXISTS idx_people_patronymic_metaphone;
+DROP INDEX IF EXISTS idx_people_patronymic_phonetic;
+
+ALTER TABLE people DROP COLUMN name_translit;
+ALTER TABLE people DROP COLUMN name_metaphone;
+ALTER TABLE people DROP COLUMN name_phonetic;
+ALTER TABLE people DROP COLUMN surname_translit;
+ALTER TABLE people DROP COLUMN surname_metaphone;
+ALTER TABLE people DROP COLUMN surname_phonetic;
+ALTER TABLE people DROP COLUMN patronymic_translit;
+ALTER TABLE people DROP COLUMN patronymic_metaphone;
+ALTER TABLE people DROP COLUMN patronymic_phonetic;
@@ -0,0 +1,4 @@
+-- В SQLite нет pg_trgm: поиск дубликатов сравнивает только записи
+-- с одинаковой первой буквой транслитерированной фамилии
+CREATE INDEX IF NOT EXISTS idx_people_surname_initial
+    ON people (substr(surname_translit, 1, 1));
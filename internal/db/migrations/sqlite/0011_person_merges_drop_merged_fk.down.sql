@@ -0,0 +1,18 @@
+CREATE TABLE person_merges_old (
+    id          integer PRIMARY KEY AUTOINCREMENT,
+    survivor_id integer NOT NULL REFERENCES people (id),
+    merged_id   integer NOT NULL REFERENCES people (id),
+    snapshot    text NOT NULL,
+    fields      text NOT NULL,
+    created_at  datetime NOT NULL
+);
+
+-- Записи о слитых людях, которых уже нет, не дали бы вернуть ссылку
+INSERT INTO person_merges_old (id, survivor_id, merged_id, snapshot, fields, created_at)
+SELECT id, survivor_id, merged_id, snapshot, fields, created_at FROM person_merges
+WHERE merged_id IN (SELECT id FROM people);
+
+DROP TABLE person_merges;
+ALTER TABLE person_merges_old RENAME TO person_merges;
+
+CREATE INDEX IF NOT EXISTS idx_person_merges_survivor_id ON person_merges (survivor_id);
@@ -0,0 +1,19 @@
+-- Журнал слияний хранит снимок слитой записи, поэтому ссылка на нее не нужна
+-- и не должна мешать физически удалить слитую запись, сохранив журнал.
+-- SQLite не умеет удалять ограничения, таблица пересоздается
+CREATE TABLE person_merges_new (
+    id          integer PRIMARY KEY AUTOINCREMENT,
+    survivor_id integer NOT NULL REFERENCES people (id),
+    merged_id   integer NOT NULL,
+    snapshot    text NOT NULL,
+    fields      text NOT NULL,
+    created_at  datetime NOT NULL
+);
+
+INSERT INTO person_merges_new (id, survivor_id, merged_id, snapshot, fields, created_at)
+SELECT id, survivor_id, merged_id, snapshot, fields, created_at FROM person_merges;
+
+DROP TABLE person_merges;
+ALTER TABLE person_merges_new RENAME TO person_merges;
+
+CREATE INDEX IF NOT EXISTS idx_person_merges_survivor_id ON person_merges (survivor_id);
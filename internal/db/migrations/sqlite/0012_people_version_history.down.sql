@@ -0,0 +1,2 @@
+-- Версии только увеличивались, откатывать нечего
+SELECT 1;
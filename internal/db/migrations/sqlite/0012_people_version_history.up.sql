@@ -0,0 +1,5 @@
+-- Номер версии в истории теперь равен people.version. Удаление раньше не
+-- увеличивало people.version, поэтому такие записи догоняют историю
+UPDATE people
+SET version = (SELECT MAX(version) FROM person_versions WHERE person_id = people.id)
+WHERE version < (SELECT MAX(version) FROM person_versions WHERE person_id = people.id);
@@ -0,0 +1,62 @@
+package db
+
+import (
+	"database/sql/driver"
+	"strings"
+
+	"people-service/internal/names"
+
+	sqlite "github.com/glebarez/go-sqlite"
+)
+
+func init() {
+	// Встроенный LOWER в SQLite работает только с ASCII, поэтому для
+	// регистронезависимого поиска по кириллице нужна своя функция
+	sqlite.MustRegisterDeterministicScalarFunction("unicode_lower", 1,
+		func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+			switch v := args[0].(type) {
+			case string:
+				return strings.ToLower(v), nil
+			case []byte:
+				return strings.ToLower(string(v)), nil
+			default:
+				return v, nil
+			}
+		},
+	)
+
+	// Триграммное сходство как similarity из pg_trgm, чтобы поиск дубликатов
+	// считал оценку одним SQL на обоих диалектах
+	sqlite.MustRegisterDeterministicScalarFunction("similarity", 2,
+		func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+			a, okA := sqliteText(args[0])
+			b, okB := sqliteText(args[1])
+			if !okA || !okB {
+				return nil, nil
+			}
+			return names.Similarity(a, b), nil
+		},
+	)
+}
+
+// sqliteText возвращает строковое значение аргумента функции; false для NULL
+// и нестроковых значений
+func sqliteText(v driver.Value) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case []byte:
+		return string(v), true
+	default:
+		return "", false
+	}
+}
+
+// sqliteDSN включает ожидание блокировки вместо немедленной ошибки SQLITE_BUSY
+func sqliteDSN(path string) string {
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	return path + sep + "_pragma=busy_timeout(5000)"
+}
@@ -0,0 +1,208 @@
+// Package dedupe ищет вероятные дубликаты людей и объединяет их записи
+package dedupe
+
+import (
+	"fmt"
+	"sort"
+
+	"people-service/internal/models"
+	"people-service/internal/names"
+)
+
+// DefaultThreshold минимальная оценка, при которой пара считается дубликатом
+const DefaultThreshold = 0.6
+
+// Веса частей имени в итоговой оценке; хранилище повторяет их в SQL
+const (
+	NameWeight       = 0.4
+	SurnameWeight    = 0.5
+	PatronymicWeight = 0.1
+)
+
+// Pair пара вероятных дубликатов
+type Pair struct {
+	A     uint    `json:"a"`
+	B     uint    `json:"b"`
+	Score float64 `json:"score"`
+}
+
+// Cluster группа записей, связанных парами с оценкой не ниже порога
+type Cluster struct {
+	Score  float64         `json:"score"` // Наибольшая оценка пары в группе
+	People []models.Person `json:"people"`
+	Pairs  []Pair          `json:"pairs"`
+}
+
+// ClusterPage группы, собранные из одной страницы пар. Пары идут от самых
+// похожих, поэтому большая группа может продолжиться на следующей странице
+type ClusterPage struct {
+	Data    []Cluster `json:"data"`
+	Page    int       `json:"page"`
+	Limit   int       `json:"limit"` // Число пар на странице
+	HasNext bool      `json:"has_next"`
+}
+
+// Score оценивает сходство двух людей по триграммам имени, фамилии и
+// отчества после транслитерации. Отчество учитывается, только если оно
+// есть у обоих
+func Score(a, b models.Person) float64 {
+	score := NameWeight*names.Similarity(a.Name, b.Name) +
+		SurnameWeight*names.Similarity(a.Surname, b.Surname)
+	weight := NameWeight + SurnameWeight
+
+	if a.Patronymic != nil && b.Patronymic != nil && *a.Patronymic != "" && *b.Patronymic != "" {
+		score += PatronymicWeight * names.Similarity(*a.Patronymic, *b.Patronymic)
+		weight += PatronymicWeight
+	}
+	return score / weight
+}
+
+// MinSurnameSimilarity наименьшее сходство фамилий, при котором пара еще
+// может набрать threshold, если имя и отчество совпадают полностью
+func MinSurnameSimilarity(threshold float64) float64 {
+	return max(0, (threshold-NameWeight-PatronymicWeight)/SurnameWeight)
+}
+
+// FindDuplicates группирует вероятные дубликаты среди people
+func FindDuplicates(people []models.Person, threshold float64) []Cluster {
+	return Clusters(Pairs(people, threshold), people)
+}
+
+// Pairs возвращает пары с оценкой не ниже threshold от самых похожих.
+// Сравниваются только записи с одинаковой первой буквой транслитерированной
+// фамилии, чтобы не считать все пары
+func Pairs(people []models.Person, threshold float64) []Pair {
+	blocks := make(map[string][]int)
+	for i, p := range people {
+		surname := []rune(names.Normalize(p.Surname))
+		key := ""
+		if len(surname) > 0 {
+			key = string(surname[0])
+		}
+		blocks[key] = append(blocks[key], i)
+	}
+
+	pairs := []Pair{}
+	for _, block := range blocks {
+		for x := 0; x < len(block); x++ {
+			for y := x + 1; y < len(block); y++ {
+				a, b := people[block[x]], people[block[y]]
+				if a.ID > b.ID {
+					a, b = b, a
+				}
+				if score := Score(a, b); score >= threshold {
+					pairs = append(pairs, Pair{A: a.ID, B: b.ID, Score: score})
+				}
+			}
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].Score != pairs[j].Score {
+			return pairs[i].Score > pairs[j].Score
+		}
+		if pairs[i].A != pairs[j].A {
+			return pairs[i].A < pairs[j].A
+		}
+		return pairs[i].B < pairs[j].B
+	})
+	return pairs
+}
+
+// Clusters объединяет пары в группы связанных записей; people должны
+// содержать всех людей, упомянутых в pairs
+func Clusters(pairs []Pair, people []models.Person) []Cluster {
+	parent := make(map[uint]uint)
+	for _, pair := range pairs {
+		parent[pair.A], parent[pair.B] = pair.A, pair.B
+	}
+	var find func(uint) uint
+	find = func(id uint) uint {
+		if parent[id] != id {
+			parent[id] = find(parent[id])
+		}
+		return parent[id]
+	}
+	for _, pair := range pairs {
+		parent[find(pair.A)] = find(pair.B)
+	}
+
+	byRoot := make(map[uint]*Cluster)
+	for _, pair := range pairs {
+		root := find(pair.A)
+		c, ok := byRoot[root]
+		if !ok {
+			c = &Cluster{}
+			byRoot[root] = c
+		}
+		c.Pairs = append(c.Pairs, pair)
+		c.Score = max(c.Score, pair.Score)
+	}
+	for _, p := range people {
+		if _, paired := parent[p.ID]; !paired {
+			continue
+		}
+		if c, ok := byRoot[find(p.ID)]; ok {
+			c.People = append(c.People, p)
+		}
+	}
+
+	clusters := make([]Cluster, 0, len(byRoot))
+	for _, c := range byRoot {
+		sort.SliceStable(c.Pairs, func(i, j int) bool { return c.Pairs[i].Score > c.Pairs[j].Score })
+		clusters = append(clusters, *c)
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		if clusters[i].Score != clusters[j].Score {
+			return clusters[i].Score > clusters[j].Score
+		}
+		return clusters[i].firstID() < clusters[j].firstID()
+	})
+	return clusters
+}
+
+// firstID наименьший ID записи в группе; считается по парам, так как
+// записи могли удалить после выбора пар
+func (c Cluster) firstID() uint {
+	first := c.Pairs[0].A
+	for _, pair := range c.Pairs {
+		first = min(first, pair.A)
+	}
+	return first
+}
+
+// MergeableFields поля, значение которых можно взять из другой записи при слиянии
+var MergeableFields = []string{"name", "surname", "patronymic", "age", "gender", "nationality", "external_id"}
+
+// ApplyFields копирует в survivor поля из записей-источников согласно fields
+// (имя поля -> ID источника)
+func ApplyFields(survivor *models.Person, sources map[uint]models.Person, fields map[string]uint) error {
+	for field, sourceID := range fields {
+		if sourceID == survivor.ID {
+			continue
+		}
+		source, ok := sources[sourceID]
+		if !ok {
+			return fmt.Errorf("field %s refers to record %d which is not being merged", field, sourceID)
+		}
+
+		switch field {
+		case "name":
+			survivor.Name = source.Name
+		case "surname":
+			survivor.Surname = source.Surname
+		case "patronymic":
+			survivor.Patronymic = source.Patronymic
+		case "age":
+			survivor.Age = source.Age
+		case "gender":
+			survivor.Gender = source.Gender
+		case "nationality":
+			survivor.Nationality = source.Nationality
+		case "external_id":
+			survivor.ExternalID = source.ExternalID
+		default:
+			return fmt.Errorf("field %s cannot be merged", field)
+		}
+	}
+	return nil
+}
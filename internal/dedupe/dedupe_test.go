@@ -0,0 +1,62 @@
+package dedupe_test
+
+import (
+	"people-service/internal/dedupe"
+	"people-service/internal/models"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func person(id uint, name, surname string) models.Person {
+	return models.Person{Model: gorm.Model{ID: id}, Name: name, Surname: surname}
+}
+
+func TestFindDuplicates(t *testing.T) {
+	people := []models.Person{
+		person(1, "Dmitriy", "Ushakov"),
+		person(2, "Anna", "Ivanova"),
+		person(3, "Dmitry", "Ushakov"),
+		person(4, "Дмитрий", "Ушаков"),
+		person(5, "Boris", "Ushakov"),
+	}
+
+	clusters := dedupe.FindDuplicates(people, dedupe.DefaultThreshold)
+	if len(clusters) != 1 {
+		t.Fatalf("FindDuplicates() = %d clusters, want 1: %+v", len(clusters), clusters)
+	}
+
+	got := make(map[uint]bool)
+	for _, p := range clusters[0].People {
+		got[p.ID] = true
+	}
+	for _, id := range []uint{1, 3, 4} {
+		if !got[id] {
+			t.Errorf("cluster is missing person %d", id)
+		}
+	}
+	if got[5] || got[2] {
+		t.Errorf("cluster contains unrelated people: %v", got)
+	}
+}
+
+func TestApplyFields(t *testing.T) {
+	survivor := person(1, "Dmitriy", "Ushakov")
+	source := person(3, "Dmitry", "Ushakov")
+	source.Age = 42
+
+	err := dedupe.ApplyFields(&survivor, map[uint]models.Person{3: source}, map[string]uint{"age": 3, "name": 1})
+	if err != nil {
+		t.Fatalf("ApplyFields() error = %v", err)
+	}
+	if survivor.Age != 42 || survivor.Name != "Dmitriy" {
+		t.Errorf("ApplyFields() survivor = %+v", survivor)
+	}
+
+	if err := dedupe.ApplyFields(&survivor, map[uint]models.Person{3: source}, map[string]uint{"id": 3}); err == nil {
+		t.Error("ApplyFields() with unknown field: want error")
+	}
+	if err := dedupe.ApplyFields(&survivor, map[uint]models.Person{3: source}, map[string]uint{"age": 7}); err == nil {
+		t.Error("ApplyFields() with foreign source: want error")
+	}
+}
@@ -0,0 +1,167 @@
+package enrich
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// CassetteMode режим работы с записью HTTP-трафика обогащения
+type CassetteMode string
+
+const (
+	CassetteOff    CassetteMode = ""
+	CassetteRecord CassetteMode = "record"
+	CassetteReplay CassetteMode = "replay"
+)
+
+// ErrNotRecorded запрос отсутствует в кассете
+var ErrNotRecorded = errors.New("request not recorded in cassette")
+
+// Interaction одна строка кассеты: запрос и полученный ответ
+type Interaction struct {
+	Method      string `json:"method"`
+	URL         string `json:"url"`
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        string `json:"body"`
+}
+
+// Recorder пропускает запросы к настоящим API и дописывает ответы в JSONL-файл
+type Recorder struct {
+	next http.RoundTripper
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewRecorder открывает кассету на дозапись; next == nil означает http.DefaultTransport
+func NewRecorder(path string, next http.RoundTripper) (*Recorder, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Recorder{next: next, file: file}, nil
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	line, err := json.Marshal(Interaction{
+		Method:      req.Method,
+		URL:         req.URL.String(),
+		Status:      resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+		Body:        string(body),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.file.Write(append(line, '\n')); err != nil {
+		return nil, fmt.Errorf("failed to record interaction: %w", err)
+	}
+	return resp, nil
+}
+
+// Close сбрасывает записанные ответы на диск и закрывает файл кассеты
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return errors.Join(r.file.Sync(), r.file.Close())
+}
+
+// Replayer отвечает на запросы из кассеты без обращения к сети. Повторные
+// запросы получают записанные ответы по порядку, последний ответ повторяется
+type Replayer struct {
+	mu           sync.Mutex
+	interactions map[string][]Interaction
+	served       map[string]int
+}
+
+// LoadCassette читает кассету, записанную Recorder
+func LoadCassette(path string) (*Replayer, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	r := &Replayer{
+		interactions: make(map[string][]Interaction),
+		served:       make(map[string]int),
+	}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var in Interaction
+		if err := json.Unmarshal(scanner.Bytes(), &in); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, lineNo, err)
+		}
+		key := interactionKey(in.Method, in.URL)
+		r.interactions[key] = append(r.interactions[key], in)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := interactionKey(req.Method, req.URL.String())
+
+	r.mu.Lock()
+	recorded := r.interactions[key]
+	if len(recorded) == 0 {
+		r.mu.Unlock()
+		return nil, fmt.Errorf("%s %s: %w", req.Method, req.URL, ErrNotRecorded)
+	}
+	in := recorded[min(r.served[key], len(recorded)-1)]
+	r.served[key]++
+	r.mu.Unlock()
+
+	header := make(http.Header)
+	if in.ContentType != "" {
+		header.Set("Content-Type", in.ContentType)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", in.Status, http.StatusText(in.Status)),
+		StatusCode:    in.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader([]byte(in.Body))),
+		ContentLength: int64(len(in.Body)),
+		Request:       req,
+	}, nil
+}
+
+func interactionKey(method, url string) string {
+	return method + " " + url
+}
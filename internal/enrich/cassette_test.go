@@ -0,0 +1,57 @@
+package enrich_test
+
+import (
+	"errors"
+	"net/http"
+	"path/filepath"
+	"people-service/internal/enrich"
+	"people-service/internal/enrich/enrichtest"
+	"testing"
+)
+
+func TestCassetteRecordReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.jsonl")
+
+	srv := enrichtest.NewServer()
+	srv.Set("Anna", enrichtest.Person{Age: enrichtest.Age(31), Gender: "female", GenderProbability: 0.98})
+
+	provider := srv.Provider()
+	recorder, err := enrich.NewRecorder(path, provider.Client.Transport)
+	if err != nil {
+		t.Fatalf("NewRecorder() error = %v", err)
+	}
+	provider.Client = &http.Client{Transport: recorder}
+
+	recorded := make(map[enrich.Attribute]string)
+	for _, attr := range enrich.Attributes {
+		p, err := provider.Predict(attr, enrich.Query{Name: "Anna"})
+		if err != nil {
+			t.Fatalf("Predict(%s) error = %v", attr, err)
+		}
+		recorded[attr] = p.Value
+	}
+	recorder.Close()
+
+	// Воспроизведение не должно требовать сети
+	srv.Close()
+
+	replayer, err := enrich.LoadCassette(path)
+	if err != nil {
+		t.Fatalf("LoadCassette() error = %v", err)
+	}
+	provider.Client = &http.Client{Transport: replayer}
+
+	for _, attr := range enrich.Attributes {
+		p, err := provider.Predict(attr, enrich.Query{Name: "Anna"})
+		if err != nil {
+			t.Fatalf("replayed Predict(%s) error = %v", attr, err)
+		}
+		if p.Value != recorded[attr] {
+			t.Errorf("replayed %s = %q, want %q", attr, p.Value, recorded[attr])
+		}
+	}
+
+	if _, err := provider.Predict(enrich.AttributeAge, enrich.Query{Name: "Boris"}); !errors.Is(err, enrich.ErrNotRecorded) {
+		t.Errorf("Predict() for unrecorded name error = %v, want ErrNotRecorded", err)
+	}
+}
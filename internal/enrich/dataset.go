@@ -0,0 +1,81 @@
+package enrich
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// DatasetEntry запись локального справочника имен
+type DatasetEntry struct {
+	Name                   string  `json:"name"`
+	Age                    int     `json:"age,omitempty"`
+	Gender                 string  `json:"gender,omitempty"`
+	GenderProbability      float64 `json:"gender_probability,omitempty"`
+	Nationality            string  `json:"nationality,omitempty"`
+	NationalityProbability float64 `json:"nationality_probability,omitempty"`
+}
+
+// Dataset провайдер на основе локального справочника имен
+type Dataset struct {
+	name    string
+	entries map[string]DatasetEntry
+}
+
+// NewDataset создает провайдер из набора записей; имена сравниваются без учета регистра
+func NewDataset(name string, entries []DatasetEntry) *Dataset {
+	d := &Dataset{name: name, entries: make(map[string]DatasetEntry, len(entries))}
+	for _, e := range entries {
+		d.entries[normalizeName(e.Name)] = e
+	}
+	return d
+}
+
+// LoadDataset читает справочник из JSON-файла с массивом DatasetEntry
+func LoadDataset(path string) (*Dataset, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []DatasetEntry
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil, fmt.Errorf("invalid dataset %s: %w", path, err)
+	}
+	return NewDataset("dataset", entries), nil
+}
+
+func (d *Dataset) Name() string {
+	return d.name
+}
+
+func (d *Dataset) Predict(attr Attribute, q Query) (Prediction, error) {
+	prediction := Prediction{Attribute: attr, Source: d.name}
+
+	entry, ok := d.entries[normalizeName(q.Name)]
+	if !ok {
+		return prediction, nil
+	}
+
+	switch attr {
+	case AttributeAge:
+		if entry.Age > 0 {
+			prediction.Value = strconv.Itoa(entry.Age)
+		}
+	case AttributeGender:
+		prediction.Value = entry.Gender
+		prediction.Probability = entry.GenderProbability
+	case AttributeNationality:
+		prediction.Value = strings.ToUpper(entry.Nationality)
+		prediction.Probability = entry.NationalityProbability
+	default:
+		return prediction, fmt.Errorf("unknown attribute %q", attr)
+	}
+	return prediction, nil
+}
+
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
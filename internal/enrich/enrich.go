@@ -3,9 +3,13 @@ package enrich
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -16,92 +20,180 @@ import (
 
 //Возможности:
 // - Получение возраста по имени (GetAge)
-// - Определение пола по имени (GetGender)
-// - Определение национальности по имени (GetNationality)
+// - Определение пола по имени, фамилии и отчеству (GetGender)
+// - Определение национальности по имени и фамилии (GetNationality)
 // - Автоматическое кеширование результатов на 24 часа
+// - Объединение нескольких источников в ансамбль (Ensemble)
+// - Правила по окончаниям фамилии и отчества (NameRules)
 //Основная задумка:
 //При добавлении человека с именем, которое уже было, во второй раз данные будут браться из Redis (внешние API не вызываются)
 
-var (
-	RedisClient *redis.Client
-	ctx         = context.Background()
-)
-
-func InitRedis(addr string) error {
-	RedisClient = redis.NewClient(&redis.Options{
+// NewRedisClient подключается к Redis и проверяет соединение
+func NewRedisClient(addr, password string, db int) (*redis.Client, error) {
+	client := redis.NewClient(&redis.Options{
 		Addr:     addr,
-		Password: "",
-		DB:       0,
+		Password: password,
+		DB:       db,
 	})
 
-	_, err := RedisClient.Ping(ctx).Result()
-	return err
+	if _, err := client.Ping(context.Background()).Result(); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
+// ErrRateLimited внешний API отклонил запрос из-за превышения лимита
+var ErrRateLimited = errors.New("enrichment API rate limit exceeded")
+
+// APIProvider обращается к agify.io, genderize.io и nationalize.io.
+// Без Cache ответы не кешируются
+type APIProvider struct {
+	AgifyURL       string
+	GenderizeURL   string
+	NationalizeURL string
+	Client         *http.Client
+	Cache          Cache
+	TTL            time.Duration
+
+	// NationalityBySurname отправляет в nationalize.io фамилию вместо имени,
+	// если она известна: фамилия точнее указывает на происхождение
+	NationalityBySurname bool
+}
+
+// NewAPIProvider создает провайдер с публичными адресами API
+func NewAPIProvider() *APIProvider {
+	return &APIProvider{
+		AgifyURL:       "https://api.agify.io",
+		GenderizeURL:   "https://api.genderize.io",
+		NationalizeURL: "https://api.nationalize.io",
+		Client:         &http.Client{Timeout: 10 * time.Second},
+		TTL:            24 * time.Hour,
+	}
+}
+
+func (p *APIProvider) Name() string {
+	return "api"
 }
 
-func getCachedData(key string, apiURL string, target interface{}, expire time.Duration) error {
-	val, err := RedisClient.Get(ctx, key).Bytes()
-	if err == nil {
-		return json.Unmarshal(val, target)
+func (p *APIProvider) Predict(attr Attribute, q Query) (Prediction, error) {
+	prediction := Prediction{Attribute: attr, Source: p.Name()}
+
+	switch attr {
+	case AttributeAge:
+		var data struct {
+			Age *int `json:"age"`
+		}
+		cache, err := p.fetch(attr, p.AgifyURL, q.Name, &data)
+		prediction.Cache = cache
+		if err != nil {
+			return prediction, err
+		}
+		if data.Age != nil {
+			prediction.Value = strconv.Itoa(*data.Age)
+		}
+	case AttributeGender:
+		var data struct {
+			Gender      string  `json:"gender"`
+			Probability float64 `json:"probability"`
+		}
+		cache, err := p.fetch(attr, p.GenderizeURL, q.Name, &data)
+		prediction.Cache = cache
+		if err != nil {
+			return prediction, err
+		}
+		prediction.Value = data.Gender
+		prediction.Probability = data.Probability
+	case AttributeNationality:
+		var data struct {
+			Country []struct {
+				CountryID   string  `json:"country_id"`
+				Probability float64 `json:"probability"`
+			} `json:"country"`
+		}
+		name := q.Name
+		if p.NationalityBySurname && q.Surname != "" {
+			name = q.Surname
+		}
+		cache, err := p.fetch(attr, p.NationalizeURL, name, &data)
+		prediction.Cache = cache
+		if err != nil {
+			return prediction, err
+		}
+		if len(data.Country) > 0 {
+			prediction.Value = data.Country[0].CountryID
+			prediction.Probability = data.Country[0].Probability
+		}
+	default:
+		return prediction, fmt.Errorf("unknown attribute %q", attr)
+	}
+
+	return prediction, nil
+}
+
+// fetch возвращает ответ API из кеша или запрашивает его и сообщает статус
+// кеша; в кеш попадают только успешные ответы
+func (p *APIProvider) fetch(attr Attribute, baseURL, name string, target interface{}) (string, error) {
+	key := fmt.Sprintf("%s:%s", attr, name)
+	if p.Cache != nil {
+		val, err := p.Cache.Get(key)
+		if err == nil {
+			return CacheHit, json.Unmarshal(val, target)
+		}
+	}
+
+	status := ""
+	if p.Cache != nil {
+		status = CacheMiss
 	}
 
-	resp, err := http.Get(apiURL)
+	resp, err := p.Client.Get(fmt.Sprintf("%s/?name=%s", strings.TrimSuffix(baseURL, "/"), url.QueryEscape(name)))
 	if err != nil {
-		return err
+		return status, err
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return err
+		return status, err
 	}
 
-	if err := RedisClient.Set(ctx, key, body, expire).Err(); err != nil {
-		return err
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return status, fmt.Errorf("%s: %w", baseURL, ErrRateLimited)
+	case resp.StatusCode != http.StatusOK:
+		return status, fmt.Errorf("%s returned status %d", baseURL, resp.StatusCode)
 	}
 
-	return json.Unmarshal(body, target)
-}
+	if err := json.Unmarshal(body, target); err != nil {
+		return status, fmt.Errorf("%s returned malformed body: %w", baseURL, err)
+	}
 
-func GetAge(name string) (int, error) {
-	var data struct {
-		Age int `json:"age"`
+	if p.Cache != nil {
+		if err := p.Cache.Set(key, body, p.TTL); err != nil {
+			return status, err
+		}
 	}
-	err := getCachedData(
-		fmt.Sprintf("age:%s", name),
-		fmt.Sprintf("https://api.agify.io/?name=%s", name),
-		&data,
-		24*time.Hour,
-	)
-	return data.Age, err
+	return status, nil
 }
 
-func GetGender(name string) (string, error) {
-	var data struct {
-		Gender string `json:"gender"`
+func GetAge(provider Provider, q Query) (int, error) {
+	prediction, err := provider.Predict(AttributeAge, q)
+	if err != nil || prediction.Empty() {
+		return 0, err
 	}
-	err := getCachedData(
-		fmt.Sprintf("gender:%s", name),
-		fmt.Sprintf("https://api.genderize.io/?name=%s", name),
-		&data,
-		24*time.Hour,
-	)
-	return data.Gender, err
+	return strconv.Atoi(prediction.Value)
 }
 
-func GetNationality(name string) (string, error) {
-	var data struct {
-		Country []struct {
-			CountryID string `json:"country_id"`
-		} `json:"country"`
-	}
-	err := getCachedData(
-		fmt.Sprintf("nationality:%s", name),
-		fmt.Sprintf("https://api.nationalize.io/?name=%s", name),
-		&data,
-		24*time.Hour,
-	)
-	if len(data.Country) > 0 {
-		return data.Country[0].CountryID, nil
+func GetGender(provider Provider, q Query) (string, error) {
+	prediction, err := provider.Predict(AttributeGender, q)
+	return prediction.Value, err
+}
+
+func GetNationality(provider Provider, q Query) (string, error) {
+	prediction, err := provider.Predict(AttributeNationality, q)
+	if !prediction.Empty() {
+		return prediction.Value, nil
 	}
 	return "unknown", err
 }
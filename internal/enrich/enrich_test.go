@@ -0,0 +1,78 @@
+package enrich_test
+
+import (
+	"errors"
+	"people-service/internal/enrich"
+	"people-service/internal/enrich/enrichtest"
+	"testing"
+)
+
+func TestAPIProviderPredict(t *testing.T) {
+	srv := enrichtest.NewServer()
+	defer srv.Close()
+	srv.Set("Dmitriy", enrichtest.Person{
+		Age:               enrichtest.Age(42),
+		Gender:            "male",
+		GenderProbability: 0.99,
+		Countries:         []enrichtest.Country{{CountryID: "UA", Probability: 0.4}, {CountryID: "RU", Probability: 0.3}},
+	})
+
+	provider := srv.Provider()
+	query := enrich.Query{Name: "Dmitriy"}
+
+	tests := []struct {
+		attr enrich.Attribute
+		want string
+	}{
+		{enrich.AttributeAge, "42"},
+		{enrich.AttributeGender, "male"},
+		{enrich.AttributeNationality, "UA"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.attr), func(t *testing.T) {
+			got, err := provider.Predict(tt.attr, query)
+			if err != nil {
+				t.Fatalf("Predict() error = %v", err)
+			}
+			if got.Value != tt.want {
+				t.Errorf("Predict() value = %q, want %q", got.Value, tt.want)
+			}
+		})
+	}
+
+	unknown, err := provider.Predict(enrich.AttributeAge, enrich.Query{Name: "Zzyzx"})
+	if err != nil || !unknown.Empty() {
+		t.Errorf("Predict() for unknown name = %+v, %v; want empty prediction", unknown, err)
+	}
+}
+
+func TestAPIProviderFailures(t *testing.T) {
+	srv := enrichtest.NewServer()
+	defer srv.Close()
+	srv.Set("Anna", enrichtest.Person{Gender: "female", GenderProbability: 0.98})
+
+	provider := srv.Provider()
+	provider.Cache = enrich.NewMemoryCache()
+	query := enrich.Query{Name: "Anna"}
+
+	srv.RateLimit(enrichtest.Genderize, 1)
+	if _, err := provider.Predict(enrich.AttributeGender, query); !errors.Is(err, enrich.ErrRateLimited) {
+		t.Fatalf("Predict() error = %v, want ErrRateLimited", err)
+	}
+
+	srv.Malformed(enrichtest.Genderize, 1)
+	if _, err := provider.Predict(enrich.AttributeGender, query); err == nil {
+		t.Fatal("Predict() with malformed body: want error")
+	}
+
+	// Сбои не должны попадать в кеш: следующий запрос идет в API и кешируется
+	for i := 0; i < 2; i++ {
+		got, err := provider.Predict(enrich.AttributeGender, query)
+		if err != nil || got.Value != "female" {
+			t.Fatalf("Predict() = %+v, %v; want female", got, err)
+		}
+	}
+	if n := srv.Requests(enrichtest.Genderize); n != 3 {
+		t.Errorf("genderize requests = %d, want 3", n)
+	}
+}
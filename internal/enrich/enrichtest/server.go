@@ -0,0 +1,220 @@
+// Package enrichtest поднимает локальный сервер, эмулирующий agify.io,
+// genderize.io и nationalize.io, чтобы тесты обогащения работали без сети
+package enrichtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"people-service/internal/enrich"
+)
+
+// API идентифицирует эмулируемый сервис
+type API string
+
+const (
+	Agify       API = "agify"
+	Genderize   API = "genderize"
+	Nationalize API = "nationalize"
+)
+
+// MaxBatchSize максимальное число имен в одном batch-запросе, как у реальных API
+const MaxBatchSize = 10
+
+// Country вероятность принадлежности к стране
+type Country struct {
+	CountryID   string  `json:"country_id"`
+	Probability float64 `json:"probability"`
+}
+
+// Person заготовленный ответ для имени
+type Person struct {
+	Age               *int
+	Gender            string
+	GenderProbability float64
+	Countries         []Country
+}
+
+// Server фейковый сервер трех API обогащения
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	people    map[string]Person
+	latency   time.Duration
+	rateLimit map[API]int
+	malformed map[API]int
+	requests  map[API]int
+}
+
+// NewServer запускает сервер; его нужно остановить через Close
+func NewServer() *Server {
+	s := &Server{
+		people:    make(map[string]Person),
+		rateLimit: make(map[API]int),
+		malformed: make(map[API]int),
+		requests:  make(map[API]int),
+	}
+
+	mux := http.NewServeMux()
+	for _, api := range []API{Agify, Genderize, Nationalize} {
+		mux.HandleFunc("/"+string(api)+"/", s.handler(api))
+	}
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// URL возвращает базовый адрес эмулируемого API
+func (s *Server) URL(api API) string {
+	return s.Server.URL + "/" + string(api)
+}
+
+// Provider возвращает провайдер, направленный на этот сервер, без кеша
+func (s *Server) Provider() *enrich.APIProvider {
+	p := enrich.NewAPIProvider()
+	p.AgifyURL = s.URL(Agify)
+	p.GenderizeURL = s.URL(Genderize)
+	p.NationalizeURL = s.URL(Nationalize)
+	p.Client = s.Client()
+	return p
+}
+
+// Set задает ответ для имени во всех трех API
+func (s *Server) Set(name string, person Person) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.people[strings.ToLower(name)] = person
+}
+
+// SetLatency задерживает каждый ответ на d
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// RateLimit отвечает 429 на следующие n запросов к api
+func (s *Server) RateLimit(api API, n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rateLimit[api] = n
+}
+
+// Malformed отвечает невалидным JSON на следующие n запросов к api
+func (s *Server) Malformed(api API, n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.malformed[api] = n
+}
+
+// Requests возвращает число запросов, полученных api
+func (s *Server) Requests(api API) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[api]
+}
+
+func (s *Server) handler(api API) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests[api]++
+		latency := s.latency
+		limited := s.consume(s.rateLimit, api)
+		malformed := s.consume(s.malformed, api)
+		s.mu.Unlock()
+
+		if latency > 0 {
+			select {
+			case <-time.After(latency):
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case limited:
+			writeError(w, http.StatusTooManyRequests, "Request limit reached")
+			return
+		case malformed:
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"count": 1, "name": `))
+			return
+		}
+
+		query := r.URL.Query()
+		if batch, ok := query["name[]"]; ok {
+			if len(batch) > MaxBatchSize {
+				writeError(w, http.StatusUnprocessableEntity, "Invalid 'name' parameter")
+				return
+			}
+			results := make([]any, len(batch))
+			for i, name := range batch {
+				results[i] = s.response(api, name)
+			}
+			_ = json.NewEncoder(w).Encode(results)
+			return
+		}
+
+		name := query.Get("name")
+		if name == "" {
+			writeError(w, http.StatusUnprocessableEntity, "Missing 'name' parameter")
+			return
+		}
+		_ = json.NewEncoder(w).Encode(s.response(api, name))
+	}
+}
+
+// consume уменьшает счетчик сбоев и сообщает, нужно ли сбоить сейчас
+func (s *Server) consume(counters map[API]int, api API) bool {
+	if counters[api] <= 0 {
+		return false
+	}
+	counters[api]--
+	return true
+}
+
+func (s *Server) response(api API, name string) map[string]any {
+	s.mu.Lock()
+	person, known := s.people[strings.ToLower(name)]
+	s.mu.Unlock()
+
+	count := 0
+	if known {
+		count = 1000
+	}
+	body := map[string]any{"count": count, "name": name}
+
+	switch api {
+	case Agify:
+		body["age"] = person.Age
+	case Genderize:
+		if person.Gender != "" {
+			body["gender"] = person.Gender
+		} else {
+			body["gender"] = nil
+		}
+		body["probability"] = person.GenderProbability
+	case Nationalize:
+		countries := person.Countries
+		if countries == nil {
+			countries = []Country{}
+		}
+		body["country"] = countries
+	}
+	return body
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
+
+// Age возвращает указатель на возраст для Person.Age
+func Age(age int) *int {
+	return &age
+}
@@ -0,0 +1,177 @@
+package enrich
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"sync"
+)
+
+// Strategy определяет способ объединения ответов участников ансамбля
+type Strategy string
+
+const (
+	// StrategyWeightedVote суммирует вес*уверенность по каждому значению;
+	// возраст усредняется с весами
+	StrategyWeightedVote Strategy = "weighted_vote"
+	// StrategyHighestConfidence берет ответ с наибольшей вес*уверенность
+	StrategyHighestConfidence Strategy = "highest_confidence"
+)
+
+// DefaultAgeTolerance допустимый разброс возраста, после которого
+// ответы участников считаются расходящимися
+const DefaultAgeTolerance = 5
+
+// Member участник ансамбля
+type Member struct {
+	Provider Provider
+	Weight   float64
+}
+
+// Ensemble опрашивает несколько провайдеров и объединяет их ответы
+type Ensemble struct {
+	Members      []Member
+	Strategy     Strategy
+	AgeTolerance int
+}
+
+// NewEnsemble создает ансамбль с указанной стратегией
+func NewEnsemble(strategy Strategy, members ...Member) *Ensemble {
+	return &Ensemble{
+		Members:      members,
+		Strategy:     strategy,
+		AgeTolerance: DefaultAgeTolerance,
+	}
+}
+
+func (e *Ensemble) Name() string {
+	return "ensemble"
+}
+
+// Predict опрашивает всех участников параллельно. Ошибка возвращается,
+// только если не ответил ни один участник
+func (e *Ensemble) Predict(attr Attribute, q Query) (Prediction, error) {
+	predictions := make([]Prediction, len(e.Members))
+	errs := make([]error, len(e.Members))
+
+	var wg sync.WaitGroup
+	for i, m := range e.Members {
+		wg.Add(1)
+		go func(i int, m Member) {
+			defer wg.Done()
+			predictions[i], errs[i] = m.Provider.Predict(attr, q)
+		}(i, m)
+	}
+	wg.Wait()
+
+	result := Prediction{Attribute: attr, Source: e.Name()}
+	var failed []error
+	for i, m := range e.Members {
+		if errs[i] != nil {
+			failed = append(failed, errs[i])
+			continue
+		}
+		result.Cache = mergeCacheStatus(result.Cache, predictions[i].Cache)
+		if predictions[i].Empty() {
+			continue
+		}
+		result.Votes = append(result.Votes, Vote{
+			Source:      m.Provider.Name(),
+			Value:       predictions[i].Value,
+			Probability: predictions[i].Probability,
+			Weight:      m.Weight,
+			Cache:       predictions[i].Cache,
+		})
+	}
+
+	if len(failed) == len(e.Members) && len(failed) > 0 {
+		return result, errors.Join(failed...)
+	}
+	if len(result.Votes) == 0 {
+		return result, nil
+	}
+
+	if attr == AttributeAge {
+		e.mergeAge(&result)
+	} else {
+		e.mergeCategorical(&result)
+	}
+	return result, nil
+}
+
+func (e *Ensemble) mergeCategorical(result *Prediction) {
+	scores := make(map[string]float64)
+	var order []string
+	var total float64
+	for _, v := range result.Votes {
+		score := v.Weight * voteConfidence(v)
+		if _, seen := scores[v.Value]; !seen {
+			order = append(order, v.Value)
+		}
+		scores[v.Value] += score
+		total += score
+	}
+
+	switch e.Strategy {
+	case StrategyHighestConfidence:
+		best := result.Votes[0]
+		for _, v := range result.Votes[1:] {
+			if v.Weight*voteConfidence(v) > best.Weight*voteConfidence(best) {
+				best = v
+			}
+		}
+		result.Value = best.Value
+		result.Probability = best.Probability
+	default:
+		for _, value := range order {
+			if result.Value == "" || scores[value] > scores[result.Value] {
+				result.Value = value
+			}
+		}
+		if total > 0 {
+			result.Probability = scores[result.Value] / total
+		}
+	}
+
+	result.Disagreement = len(order) > 1
+}
+
+func (e *Ensemble) mergeAge(result *Prediction) {
+	minAge, maxAge := math.MaxInt, math.MinInt
+	var sum, weights float64
+	var best Vote
+	var bestScore float64
+
+	for _, v := range result.Votes {
+		age, err := strconv.Atoi(v.Value)
+		if err != nil {
+			continue
+		}
+		minAge = min(minAge, age)
+		maxAge = max(maxAge, age)
+
+		score := v.Weight * voteConfidence(v)
+		sum += float64(age) * score
+		weights += score
+		if best.Value == "" || score > bestScore {
+			best, bestScore = v, score
+		}
+	}
+	if weights == 0 {
+		return
+	}
+
+	switch e.Strategy {
+	case StrategyHighestConfidence:
+		result.Value = best.Value
+		result.Probability = best.Probability
+	default:
+		result.Value = strconv.Itoa(int(math.Round(sum / weights)))
+	}
+
+	result.Disagreement = maxAge-minAge > e.AgeTolerance
+}
+
+func voteConfidence(v Vote) float64 {
+	return Prediction{Probability: v.Probability}.confidence()
+}
@@ -0,0 +1,107 @@
+package enrich_test
+
+import (
+	"errors"
+	"people-service/internal/enrich"
+	"testing"
+)
+
+type stubProvider struct {
+	name        string
+	predictions map[enrich.Attribute]enrich.Prediction
+	err         error
+}
+
+func (s stubProvider) Name() string { return s.name }
+
+func (s stubProvider) Predict(attr enrich.Attribute, q enrich.Query) (enrich.Prediction, error) {
+	p := s.predictions[attr]
+	p.Attribute = attr
+	p.Source = s.name
+	return p, s.err
+}
+
+func TestEnsemblePredict(t *testing.T) {
+	api := stubProvider{name: "api", predictions: map[enrich.Attribute]enrich.Prediction{
+		enrich.AttributeAge:         {Value: "40"},
+		enrich.AttributeGender:      {Value: "male", Probability: 0.6},
+		enrich.AttributeNationality: {Value: "RU", Probability: 0.3},
+	}}
+	local := stubProvider{name: "local", predictions: map[enrich.Attribute]enrich.Prediction{
+		enrich.AttributeAge:         {Value: "50"},
+		enrich.AttributeGender:      {Value: "male", Probability: 0.9},
+		enrich.AttributeNationality: {Value: "UA", Probability: 0.8},
+	}}
+	broken := stubProvider{name: "broken", err: errors.New("timeout")}
+
+	tests := []struct {
+		name             string
+		strategy         enrich.Strategy
+		members          []enrich.Member
+		attr             enrich.Attribute
+		wantValue        string
+		wantDisagreement bool
+		wantErr          bool
+	}{
+		{
+			name:      "weighted vote agrees",
+			strategy:  enrich.StrategyWeightedVote,
+			members:   []enrich.Member{{Provider: api, Weight: 1}, {Provider: local, Weight: 1}},
+			attr:      enrich.AttributeGender,
+			wantValue: "male",
+		},
+		{
+			name:             "weighted vote respects weights",
+			strategy:         enrich.StrategyWeightedVote,
+			members:          []enrich.Member{{Provider: api, Weight: 5}, {Provider: local, Weight: 1}},
+			attr:             enrich.AttributeNationality,
+			wantValue:        "RU",
+			wantDisagreement: true,
+		},
+		{
+			name:             "highest confidence",
+			strategy:         enrich.StrategyHighestConfidence,
+			members:          []enrich.Member{{Provider: api, Weight: 1}, {Provider: local, Weight: 1}},
+			attr:             enrich.AttributeNationality,
+			wantValue:        "UA",
+			wantDisagreement: true,
+		},
+		{
+			name:             "weighted age average",
+			strategy:         enrich.StrategyWeightedVote,
+			members:          []enrich.Member{{Provider: api, Weight: 1}, {Provider: local, Weight: 3}},
+			attr:             enrich.AttributeAge,
+			wantValue:        "48",
+			wantDisagreement: true,
+		},
+		{
+			name:      "failed member is skipped",
+			strategy:  enrich.StrategyWeightedVote,
+			members:   []enrich.Member{{Provider: broken, Weight: 1}, {Provider: local, Weight: 1}},
+			attr:      enrich.AttributeGender,
+			wantValue: "male",
+		},
+		{
+			name:     "all members failed",
+			strategy: enrich.StrategyWeightedVote,
+			members:  []enrich.Member{{Provider: broken, Weight: 1}},
+			attr:     enrich.AttributeGender,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := enrich.NewEnsemble(tt.strategy, tt.members...).Predict(tt.attr, enrich.Query{Name: "Ivan"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Predict() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got.Value != tt.wantValue {
+				t.Errorf("Predict() value = %q, want %q", got.Value, tt.wantValue)
+			}
+			if got.Disagreement != tt.wantDisagreement {
+				t.Errorf("Predict() disagreement = %v, want %v", got.Disagreement, tt.wantDisagreement)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package enrich
+
+import "fmt"
+
+// Attribute определяет обогащаемый атрибут человека
+type Attribute string
+
+const (
+	AttributeAge         Attribute = "age"
+	AttributeGender      Attribute = "gender"
+	AttributeNationality Attribute = "nationality"
+)
+
+// Attributes перечисляет все атрибуты в порядке обогащения
+var Attributes = []Attribute{AttributeAge, AttributeGender, AttributeNationality}
+
+// Query части имени человека, по которым строится предсказание
+type Query struct {
+	Name       string `json:"name"`
+	Surname    string `json:"surname,omitempty"`
+	Patronymic string `json:"patronymic,omitempty"`
+}
+
+// Prediction результат предсказания одного атрибута
+type Prediction struct {
+	Attribute    Attribute `json:"attribute"`              // Предсказываемый атрибут
+	Value        string    `json:"value"`                  // Значение (для возраста — число строкой)
+	Probability  float64   `json:"probability"`            // Уверенность источника, 0 если неизвестна
+	Source       string    `json:"source"`                 // Имя провайдера
+	Cache        string    `json:"cache,omitempty"`        // Статус кеша: hit, miss или пусто, если кеш не участвовал
+	Disagreement bool      `json:"disagreement,omitempty"` // Источники ансамбля разошлись во мнениях
+	Votes        []Vote    `json:"votes,omitempty"`        // Голоса участников ансамбля
+}
+
+// Статусы кеша в Prediction.Cache
+const (
+	CacheHit  = "hit"
+	CacheMiss = "miss"
+)
+
+// mergeCacheStatus объединяет статусы кеша нескольких источников:
+// промах хотя бы одного источника означает обращение к внешнему API
+func mergeCacheStatus(a, b string) string {
+	if a == CacheMiss || b == CacheMiss {
+		return CacheMiss
+	}
+	if a == CacheHit || b == CacheHit {
+		return CacheHit
+	}
+	return ""
+}
+
+// Vote голос одного участника ансамбля
+type Vote struct {
+	Source      string  `json:"source"`
+	Value       string  `json:"value"`
+	Probability float64 `json:"probability"`
+	Weight      float64 `json:"weight"`
+	Cache       string  `json:"cache,omitempty"`
+}
+
+// Empty сообщает, что источник не смог ничего предсказать
+func (p Prediction) Empty() bool {
+	return p.Value == ""
+}
+
+// confidence возвращает уверенность предсказания; источники без оценки
+// вероятности (например, agify) считаются полностью уверенными
+func (p Prediction) confidence() float64 {
+	if p.Probability > 0 {
+		return p.Probability
+	}
+	return 1
+}
+
+// Provider источник предсказаний атрибутов по частям имени
+type Provider interface {
+	// Name возвращает имя источника для логов и ответов API
+	Name() string
+	// Predict предсказывает атрибут; пустое значение без ошибки означает,
+	// что источник не знает ответа
+	Predict(attr Attribute, q Query) (Prediction, error)
+}
+
+// Result предсказания всех атрибутов для одного человека
+type Result struct {
+	Age         Prediction `json:"age"`
+	Gender      Prediction `json:"gender"`
+	Nationality Prediction `json:"nationality"`
+}
+
+// Preview предсказывает все атрибуты через provider, ничего не сохраняя
+func Preview(provider Provider, q Query) (Result, error) {
+	var result Result
+	targets := map[Attribute]*Prediction{
+		AttributeAge:         &result.Age,
+		AttributeGender:      &result.Gender,
+		AttributeNationality: &result.Nationality,
+	}
+
+	for _, attr := range Attributes {
+		prediction, err := provider.Predict(attr, q)
+		if err != nil {
+			return result, fmt.Errorf("%s: %w", attr, err)
+		}
+		*targets[attr] = prediction
+	}
+	return result, nil
+}
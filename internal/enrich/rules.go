@@ -0,0 +1,146 @@
+package enrich
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Precedence определяет, как правила по фамилии и отчеству сочетаются
+// с предсказаниями по имени
+type Precedence string
+
+const (
+	// PrecedenceRules — сработавшее правило всегда побеждает
+	PrecedenceRules Precedence = "rules"
+	// PrecedenceProviders — правила используются, только если по имени ничего не найдено
+	PrecedenceProviders Precedence = "providers"
+	// PrecedenceConfidence — побеждает более уверенный ответ
+	PrecedenceConfidence Precedence = "confidence"
+)
+
+// suffixRule сопоставляет окончание части имени со значением атрибута
+type suffixRule struct {
+	suffixes    []string
+	value       string
+	probability float64
+}
+
+// Правила перечислены от более длинных окончаний к более коротким:
+// срабатывает первое совпадение. Латинские окончания берутся только
+// характерные: короткие вроде "in", "ov" или "yan" встречаются и в нерусских
+// фамилиях (Martin, Molina, Ryan). Уверенность по фамилии ниже, чем у
+// отчества, чтобы при PrecedenceConfidence не перебивать ответы источников
+var (
+	patronymicGenderRules = []suffixRule{
+		{[]string{"вна", "чна", "vna", "chna", "кызы", "kyzy", "gizi"}, "female", 0.99},
+		{[]string{"вич", "ич", "vich", "ich", "оглы", "ogly", "oglu"}, "male", 0.99},
+	}
+
+	surnameGenderRules = []suffixRule{
+		{[]string{"ская", "цкая", "skaya", "tskaya", "ова", "ева", "ёва", "ина", "ына", "ova", "eva"}, "female", 0.75},
+		{[]string{"ский", "цкий", "skiy", "skii", "ов", "ев", "ёв", "ин", "ын"}, "male", 0.7},
+	}
+
+	surnameNationalityRules = []suffixRule{
+		{[]string{"швили", "shvili", "дзе", "dze"}, "GE", 0.7},
+		{[]string{"енко", "enko", "чук", "chuk"}, "UA", 0.6},
+		{[]string{"янц", "yants", "ян"}, "AM", 0.6},
+		{[]string{"ене", "iene", "айте", "aite", "скас", "skas"}, "LT", 0.45},
+		{[]string{"ович", "евич", "ovich", "evich"}, "BY", 0.4},
+		{[]string{"ская", "цкая", "ский", "цкий", "skaya", "skiy", "ова", "ева", "ов", "ев", "ина", "ин"}, "RU", 0.45},
+	}
+)
+
+// NameRules предсказывает пол по отчеству или фамилии и национальность
+// по фамилии, используя типичные окончания
+type NameRules struct{}
+
+// NewNameRules создает провайдер правил по окончаниям
+func NewNameRules() *NameRules {
+	return &NameRules{}
+}
+
+func (r *NameRules) Name() string {
+	return "rules"
+}
+
+func (r *NameRules) Predict(attr Attribute, q Query) (Prediction, error) {
+	prediction := Prediction{Attribute: attr, Source: r.Name()}
+
+	switch attr {
+	case AttributeAge:
+		// Возраст по окончаниям не определяется
+	case AttributeGender:
+		if rule, ok := matchSuffix(q.Patronymic, patronymicGenderRules); ok {
+			prediction.Value, prediction.Probability = rule.value, rule.probability
+		} else if rule, ok := matchSuffix(q.Surname, surnameGenderRules); ok {
+			prediction.Value, prediction.Probability = rule.value, rule.probability
+		}
+	case AttributeNationality:
+		if rule, ok := matchSuffix(q.Surname, surnameNationalityRules); ok {
+			prediction.Value, prediction.Probability = rule.value, rule.probability
+		}
+	default:
+		return prediction, fmt.Errorf("unknown attribute %q", attr)
+	}
+	return prediction, nil
+}
+
+func matchSuffix(part string, rules []suffixRule) (suffixRule, bool) {
+	part = normalizeName(part)
+	if part == "" {
+		return suffixRule{}, false
+	}
+	for _, rule := range rules {
+		for _, suffix := range rule.suffixes {
+			// Окончание не должно совпадать со всем словом целиком
+			if len(part) > len(suffix) && strings.HasSuffix(part, suffix) {
+				return rule, true
+			}
+		}
+	}
+	return suffixRule{}, false
+}
+
+// Layered сочетает правила с основным провайдером согласно Precedence
+type Layered struct {
+	Rules      Provider
+	Base       Provider
+	Precedence Precedence
+}
+
+// WithRules оборачивает провайдер правилами по фамилии и отчеству
+func WithRules(base Provider, rules Provider, precedence Precedence) *Layered {
+	return &Layered{Rules: rules, Base: base, Precedence: precedence}
+}
+
+func (l *Layered) Name() string {
+	return l.Base.Name()
+}
+
+func (l *Layered) Predict(attr Attribute, q Query) (Prediction, error) {
+	ruled, err := l.Rules.Predict(attr, q)
+	if err != nil {
+		return ruled, err
+	}
+	if ruled.Empty() {
+		return l.Base.Predict(attr, q)
+	}
+	if l.Precedence == PrecedenceRules {
+		return ruled, nil
+	}
+
+	base, err := l.Base.Predict(attr, q)
+	ruled.Cache = mergeCacheStatus(ruled.Cache, base.Cache)
+	if err != nil || base.Empty() {
+		return ruled, nil
+	}
+
+	disagreement := base.Disagreement || base.Value != ruled.Value
+	if l.Precedence == PrecedenceConfidence && ruled.confidence() > base.confidence() {
+		ruled.Disagreement = disagreement
+		return ruled, nil
+	}
+	base.Disagreement = disagreement
+	return base, nil
+}
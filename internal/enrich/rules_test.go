@@ -0,0 +1,111 @@
+package enrich_test
+
+import (
+	"people-service/internal/enrich"
+	"testing"
+)
+
+func TestNameRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		attr  enrich.Attribute
+		query enrich.Query
+		want  string
+	}{
+		{
+			name:  "male patronymic",
+			attr:  enrich.AttributeGender,
+			query: enrich.Query{Name: "Саша", Surname: "Петрова", Patronymic: "Сергеевич"},
+			want:  "male",
+		},
+		{
+			name:  "female latin patronymic",
+			attr:  enrich.AttributeGender,
+			query: enrich.Query{Name: "Sasha", Patronymic: "Sergeevna"},
+			want:  "female",
+		},
+		{
+			name:  "surname gender without patronymic",
+			attr:  enrich.AttributeGender,
+			query: enrich.Query{Name: "Anna", Surname: "Ivanova"},
+			want:  "female",
+		},
+		{
+			name:  "ukrainian surname",
+			attr:  enrich.AttributeNationality,
+			query: enrich.Query{Name: "Ivan", Surname: "Shevchenko"},
+			want:  "UA",
+		},
+		{
+			name:  "latin surname ending in -in",
+			attr:  enrich.AttributeNationality,
+			query: enrich.Query{Name: "Steve", Surname: "Martin"},
+			want:  "",
+		},
+		{
+			name:  "latin surname ending in -ina",
+			attr:  enrich.AttributeGender,
+			query: enrich.Query{Name: "Antonio", Surname: "Molina"},
+			want:  "",
+		},
+		{
+			name:  "latin surname ending in -yan",
+			attr:  enrich.AttributeNationality,
+			query: enrich.Query{Name: "Paul", Surname: "Ryan"},
+			want:  "",
+		},
+		{
+			name:  "cyrillic russian surname",
+			attr:  enrich.AttributeNationality,
+			query: enrich.Query{Name: "Иван", Surname: "Иванов"},
+			want:  "RU",
+		},
+		{
+			name:  "unknown surname",
+			attr:  enrich.AttributeNationality,
+			query: enrich.Query{Name: "John", Surname: "Smith"},
+			want:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := enrich.NewNameRules().Predict(tt.attr, tt.query)
+			if err != nil {
+				t.Fatalf("Predict() error = %v", err)
+			}
+			if got.Value != tt.want {
+				t.Errorf("Predict() value = %q, want %q", got.Value, tt.want)
+			}
+		})
+	}
+}
+
+func TestLayeredPrecedence(t *testing.T) {
+	base := stubProvider{name: "api", predictions: map[enrich.Attribute]enrich.Prediction{
+		enrich.AttributeGender: {Value: "female", Probability: 0.7},
+	}}
+	query := enrich.Query{Name: "Sasha", Patronymic: "Sergeevich"}
+
+	tests := []struct {
+		precedence enrich.Precedence
+		want       string
+	}{
+		{enrich.PrecedenceRules, "male"},
+		{enrich.PrecedenceProviders, "female"},
+		{enrich.PrecedenceConfidence, "male"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.precedence), func(t *testing.T) {
+			layered := enrich.WithRules(base, enrich.NewNameRules(), tt.precedence)
+			got, err := layered.Predict(enrich.AttributeGender, query)
+			if err != nil {
+				t.Fatalf("Predict() error = %v", err)
+			}
+			if got.Value != tt.want {
+				t.Errorf("Predict() value = %q, want %q", got.Value, tt.want)
+			}
+		})
+	}
+}
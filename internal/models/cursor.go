@@ -0,0 +1,161 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCursor курсор не разбирается или выдан для другой сортировки
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// SortKey значения полей сортировки записи, на границе которой
+// остановилась страница. Заполнены только поля текущей сортировки и ID,
+// чтобы курсор не раскрывал остальные данные записи
+type SortKey struct {
+	ID          uint       `json:"id"`
+	Name        string     `json:"name,omitempty"`
+	Surname     string     `json:"surname,omitempty"`
+	Patronymic  string     `json:"patronymic,omitempty"`
+	Age         int        `json:"age,omitempty"`
+	Gender      string     `json:"gender,omitempty"`
+	Nationality string     `json:"nationality,omitempty"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+}
+
+// NewSortKey берет из p значения полей сортировки sort и ID
+func NewSortKey(p Person, sort []SortField) SortKey {
+	key := SortKey{ID: p.ID}
+	for _, field := range sort {
+		switch field.Field {
+		case "name":
+			key.Name = p.Name
+		case "surname":
+			key.Surname = p.Surname
+		case "patronymic":
+			if p.Patronymic != nil {
+				key.Patronymic = *p.Patronymic
+			}
+		case "age":
+			key.Age = p.Age
+		case "gender":
+			key.Gender = p.Gender
+		case "nationality":
+			key.Nationality = p.Nationality
+		case "created_at":
+			key.CreatedAt = &p.CreatedAt
+		case "updated_at":
+			key.UpdatedAt = &p.UpdatedAt
+		}
+	}
+	return key
+}
+
+// Value возвращает значение поля из SortableFields; отсутствующее
+// отчество представлено пустой строкой
+func (k SortKey) Value(field string) any {
+	switch field {
+	case "id":
+		return k.ID
+	case "name":
+		return k.Name
+	case "surname":
+		return k.Surname
+	case "patronymic":
+		return k.Patronymic
+	case "age":
+		return k.Age
+	case "gender":
+		return k.Gender
+	case "nationality":
+		return k.Nationality
+	case "created_at":
+		return timeValue(k.CreatedAt)
+	case "updated_at":
+		return timeValue(k.UpdatedAt)
+	}
+	return nil
+}
+
+// Person возвращает запись с полями ключа, чтобы сравнивать ее со списком
+func (k SortKey) Person() Person {
+	p := Person{
+		Name:        k.Name,
+		Surname:     k.Surname,
+		Patronymic:  &k.Patronymic,
+		Age:         k.Age,
+		Gender:      k.Gender,
+		Nationality: k.Nationality,
+	}
+	p.ID, p.CreatedAt, p.UpdatedAt = k.ID, timeValue(k.CreatedAt), timeValue(k.UpdatedAt)
+	return p
+}
+
+func timeValue(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
+
+// Cursor непрозрачная для клиента позиция в списке людей
+type Cursor struct {
+	Sort string  `json:"s"`           // Сортировка, для которой выдан курсор
+	Prev bool    `json:"p,omitempty"` // Страница перед Key, а не после
+	Key  SortKey `json:"k"`
+}
+
+// NewCursor строит курсор на страницу после (или, если prev, перед) p
+func NewCursor(p Person, sort []SortField, prev bool) Cursor {
+	return Cursor{Sort: FormatSort(sort), Prev: prev, Key: NewSortKey(p, sort)}
+}
+
+// Encode возвращает курсор в виде строки для параметра cursor
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor разбирает параметр cursor и проверяет, что он выдан
+// для сортировки sort и ключ не содержит полей вне нее
+func DecodeCursor(s string, sort []SortField) (Cursor, error) {
+	var c Cursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, ErrInvalidCursor
+	}
+	if c.Sort != FormatSort(sort) {
+		return c, ErrInvalidCursor
+	}
+	var raw struct {
+		Key map[string]json.RawMessage `json:"k"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return c, ErrInvalidCursor
+	}
+	for field := range raw.Key {
+		if !slices.ContainsFunc(sort, func(f SortField) bool { return f.Field == field }) {
+			return c, ErrInvalidCursor
+		}
+	}
+	return c, nil
+}
+
+// FormatSort записывает сортировку в виде параметра sort
+func FormatSort(sort []SortField) string {
+	items := make([]string, len(sort))
+	for i, field := range sort {
+		items[i] = field.Field
+		if field.Desc {
+			items[i] = "-" + field.Field
+		}
+	}
+	return strings.Join(items, ",")
+}
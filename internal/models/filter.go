@@ -1,11 +1,130 @@
 package models
 
+import (
+	"strings"
+	"time"
+)
+
+// Режимы сравнения имени, фамилии и отчества в PersonFilter.Match
+const (
+	MatchContains = "contains"
+	MatchPrefix   = "prefix"
+	MatchExact    = "exact"
+	// MatchTranslit сравнивает формы в латинице: "Dmitriy" находит "Дмитрий"
+	MatchTranslit = "translit"
+	// MatchPhonetic дополнительно сравнивает фонетические ключи:
+	// "Dmitriy" находит "Dmitry"
+	MatchPhonetic = "phonetic"
+)
+
 type PersonFilter struct {
-	Name        string `form:"name"`
-	Surname     string `form:"surname"`
-	Age         int    `form:"age"`
-	Gender      string `form:"gender"`
-	Nationality string `form:"nationality"`
-	Page        int    `form:"page" default:"1"`
-	Limit       int    `form:"limit" default:"10"`
+	Name       string `form:"name"`
+	Surname    string `form:"surname"`
+	Patronymic string `form:"patronymic"`
+	// Match способ сравнения name, surname и patronymic без учета регистра
+	Match string `form:"match" validate:"omitempty,oneof=contains prefix exact translit phonetic"`
+
+	Age    *int `form:"age" validate:"omitempty,min=0,max=120"`
+	AgeMin *int `form:"age_min" validate:"omitempty,min=0,max=120"`
+	AgeMax *int `form:"age_max" validate:"omitempty,min=0,max=120"`
+
+	// Gender и Nationality принимают несколько значений через запятую
+	// или повтором параметра
+	Gender      []string `form:"gender" validate:"dive,oneof=male female other"`
+	Nationality []string `form:"nationality" validate:"dive,len=2"`
+
+	// Диапазоны дат: from включительно, to не включительно
+	CreatedFrom time.Time `form:"created_from"`
+	CreatedTo   time.Time `form:"created_to"`
+	UpdatedFrom time.Time `form:"updated_from"`
+	UpdatedTo   time.Time `form:"updated_to"`
+
+	// IsNull оставляет записи, у которых перечисленные поля не заполнены
+	IsNull []string `form:"is_null" validate:"dive,oneof=patronymic gender nationality"`
+
+	// Sort поля сортировки через запятую, например surname,-age (см. ParseSort)
+	Sort string `form:"sort"`
+
+	// Cursor включает постраничный вывод по ключу сортировки вместо
+	// page; значение берется из next_cursor или prev_cursor ответа
+	Cursor string `form:"cursor"`
+	// SkipCount отключает подсчет общего числа записей
+	SkipCount bool `form:"skip_count"`
+
+	Page  int `form:"page"`
+	Limit int `form:"limit"`
+}
+
+// Paginate подставляет первую страницу и лимит по умолчанию, если они не
+// заданы, и ограничивает лимит сверху maxLimit (0 — без ограничения)
+func (f *PersonFilter) Paginate(defaultLimit, maxLimit int) {
+	f.Page, f.Limit = paginate(f.Page, f.Limit, defaultLimit, maxLimit)
+}
+
+func paginate(page, limit, defaultLimit, maxLimit int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = defaultLimit
+	}
+	if limit < 1 {
+		limit = 10
+	}
+	if maxLimit > 0 && limit > maxLimit {
+		limit = maxLimit
+	}
+	return page, limit
+}
+
+// Normalize разбивает списки через запятую и приводит пол к нижнему,
+// а национальность к верхнему регистру
+func (f *PersonFilter) Normalize() {
+	f.Gender = splitList(f.Gender, strings.ToLower)
+	f.Nationality = splitList(f.Nationality, strings.ToUpper)
+	f.IsNull = splitList(f.IsNull, strings.ToLower)
+}
+
+// MatchMode возвращает режим сравнения имен с учетом значения по умолчанию
+func (f PersonFilter) MatchMode() string {
+	if f.Match == "" {
+		return MatchContains
+	}
+	return f.Match
+}
+
+func splitList(values []string, normalize func(string) string) []string {
+	var result []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				result = append(result, normalize(item))
+			}
+		}
+	}
+	return result
+}
+
+type EnrichmentPreviewQuery struct {
+	Name       string `form:"name" validate:"required,min=2,max=100,alphaunicode"`
+	Surname    string `form:"surname" validate:"omitempty,min=2,max=100,alphaunicode"`
+	Patronymic string `form:"patronymic" validate:"omitempty,min=2,max=100,alphaunicode"`
+}
+
+// DuplicatesQuery параметры поиска дубликатов; страница и лимит считаются
+// в парах
+type DuplicatesQuery struct {
+	Threshold float64 `form:"threshold" validate:"omitempty,gt=0,lte=1"`
+	Page      int     `form:"page" validate:"omitempty,min=1"`
+	Limit     int     `form:"limit" validate:"omitempty,min=1"`
+}
+
+// Paginate подставляет страницу и лимит по умолчанию, как PersonFilter.Paginate
+func (q *DuplicatesQuery) Paginate(defaultLimit, maxLimit int) {
+	q.Page, q.Limit = paginate(q.Page, q.Limit, defaultLimit, maxLimit)
+}
+
+// Offset возвращает число пар, пропускаемых до страницы
+func (q DuplicatesQuery) Offset() int {
+	return (q.Page - 1) * q.Limit
 }
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// IdentityRule определяет, какие записи считаются одним и тем же человеком
+type IdentityRule string
+
+const (
+	// IdentityNone отключает проверку дубликатов
+	IdentityNone IdentityRule = "none"
+	// IdentityFullName — совпадение имени, фамилии и отчества без учета регистра
+	IdentityFullName IdentityRule = "full_name"
+)
+
+// ParseIdentityRule проверяет название правила из конфигурации
+func ParseIdentityRule(s string) (IdentityRule, error) {
+	switch rule := IdentityRule(s); rule {
+	case IdentityNone, IdentityFullName:
+		return rule, nil
+	default:
+		return "", fmt.Errorf("unknown identity rule %q", s)
+	}
+}
+
+// Key возвращает ключ уникальности человека или nil, если правило его не задает.
+// Записи, сохраненные до включения правила, получают ключ при запуске
+// (GormRepository.BackfillIdentityKeys)
+func (r IdentityRule) Key(p *Person) *string {
+	switch r {
+	case IdentityFullName:
+		patronymic := ""
+		if p.Patronymic != nil {
+			patronymic = *p.Patronymic
+		}
+		key := strings.Join([]string{
+			normalizeIdentityPart(p.Name),
+			normalizeIdentityPart(p.Surname),
+			normalizeIdentityPart(patronymic),
+		}, "|")
+		return &key
+	default:
+		return nil
+	}
+}
+
+// KeyOnUpdate возвращает ключ уникальности измененной записи. Запись без
+// ключа (копии дубликатов, которым ключ не достался при заполнении, или
+// записи, созданные при правиле none) сохраняет NULL, пока ее имя, фамилия и
+// отчество не меняются: иначе такую копию нельзя было бы отредактировать
+// из-за ключа, уже занятого другой записью
+func (r IdentityRule) KeyOnUpdate(before, after *Person) *string {
+	key := r.Key(after)
+	if before.IdentityKey == nil && equalKeys(r.Key(before), key) {
+		return nil
+	}
+	return key
+}
+
+func equalKeys(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func normalizeIdentityPart(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
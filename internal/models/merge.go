@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// RawJSON JSON-документ, хранящийся в текстовой колонке и отдаваемый
+// клиентам как вложенный объект, а не строка
+type RawJSON string
+
+func (j RawJSON) MarshalJSON() ([]byte, error) {
+	if j == "" {
+		return []byte("null"), nil
+	}
+	return []byte(j), nil
+}
+
+func (j *RawJSON) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*j = ""
+		return nil
+	}
+	*j = RawJSON(data)
+	return nil
+}
+
+// PersonMerge запись журнала слияния дубликатов
+type PersonMerge struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	SurvivorID uint      `json:"survivor_id"`
+	MergedID   uint      `json:"merged_id"`
+	Snapshot   RawJSON   `json:"snapshot"` // Запись MergedID до слияния
+	Fields     RawJSON   `json:"fields"`   // Выбор полей: имя поля -> ID записи-источника
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// MergeRequest запрос на слияние дубликатов в одну запись
+type MergeRequest struct {
+	SurvivorID uint            `json:"survivor_id" validate:"required"`
+	MergedIDs  []uint          `json:"merged_ids" validate:"required,min=1,dive,required"`
+	Fields     map[string]uint `json:"fields"` // Имя поля -> ID записи, из которой взять значение
+}
+
+// MergeResponse запись после слияния и журнал слияния
+type MergeResponse struct {
+	Survivor Person        `json:"survivor"`
+	Merges   []PersonMerge `json:"merges"`
+}
@@ -1,12 +1,16 @@
 package models
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"people-service/internal/enrich"
+	"people-service/internal/names"
+	"people-service/internal/phonetic"
 
 	"gorm.io/gorm"
 )
@@ -19,65 +23,105 @@ type Person struct {
 	Age         int     `json:"age" validate:"min=0,max=120"`
 	Gender      string  `json:"gender" validate:"omitempty,oneof=male female other"`
 	Nationality string  `json:"nationality" validate:"omitempty,len=2"`
-}
 
-var (
-	ErrNameRequired    = errors.New("имя обязательно")
-	ErrSurnameRequired = errors.New("фамилия обязательна")
-	ErrInvalidName     = errors.New("имя может содержать только буквы")
-	ErrInvalidAge      = errors.New("возраст должен быть от 0 до 120")
-	ErrInvalidGender   = errors.New("пол должен быть 'male', 'female' или 'other'")
-	nameRegex          = regexp.MustCompile(`^[a-zA-Zа-яА-Я\-]+$`)
-)
+	// ExternalID ключ записи во внешней системе клиента; уникален среди
+	// неудаленных записей. Арендаторов в сервисе нет, поэтому уникальность
+	// глобальная
+	ExternalID *string `json:"external_id,omitempty" validate:"omitempty,min=1,max=255"`
 
-// GetPeople возвращает список людей с фильтрацией и пагинацией
-func GetPeople(db *gorm.DB, filter PersonFilter) ([]Person, int64, error) {
-	var people []Person
-	query := db.Model(&Person{})
+	// Version увеличивается хранилищем при каждом изменении и служит ETag
+	Version int `json:"version" gorm:"not null;default:1"`
 
-	// Применяем фильтры
-	if filter.Name != "" {
-		query = query.Where("name ILIKE ?", "%"+filter.Name+"%")
-	}
-	if filter.Surname != "" {
-		query = query.Where("surname ILIKE ?", "%"+filter.Surname+"%")
-	}
-	if filter.Age > 0 {
-		query = query.Where("age = ?", filter.Age)
-	}
-	if filter.Gender != "" {
-		query = query.Where("gender = ?", strings.ToLower(filter.Gender))
-	}
-	if filter.Nationality != "" {
-		query = query.Where("nationality = ?", strings.ToUpper(filter.Nationality))
-	}
+	// IdentityKey заполняется хранилищем по IdentityRule и защищен
+	// уникальным индексом среди неудаленных записей
+	IdentityKey *string `json:"-"`
 
-	// Получаем общее количество записей (для пагинации)
-	var total int64
-	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, fmt.Errorf("ошибка получения общего количества: %w", err)
-	}
+	// Enrichment предсказания источников (enrich.Result), по которым Enrich
+	// заполнил возраст, пол и национальность; отдается через expand=enrichment
+	Enrichment RawJSON `json:"-"`
+
+	// Ключи поиска с match=translit и match=phonetic; хранилище
+	// вычисляет их при каждой записи (см. SetNameKeys)
+	NameKeys       NameKeys `json:"-" gorm:"embedded;embeddedPrefix:name_"`
+	SurnameKeys    NameKeys `json:"-" gorm:"embedded;embeddedPrefix:surname_"`
+	PatronymicKeys NameKeys `json:"-" gorm:"embedded;embeddedPrefix:patronymic_"`
+}
 
-	// Применяем пагинацию
-	if filter.Page < 1 {
-		filter.Page = 1
+// NameKeys формы части имени, по которым она находится независимо от
+// алфавита и написания
+type NameKeys struct {
+	Translit  string // Латиница в нижнем регистре (names.Normalize)
+	Metaphone string // Основной ключ Double Metaphone транслитерации
+	Phonetic  string // Ключ русского Metaphone; пусто, если в части нет кириллицы
+}
+
+// NewNameKeys вычисляет ключи поиска части имени s
+func NewNameKeys(s string) NameKeys {
+	translit := names.Normalize(s)
+	metaphone, _ := phonetic.DoubleMetaphone(translit)
+	return NameKeys{
+		Translit:  translit,
+		Metaphone: metaphone,
+		Phonetic:  phonetic.Russian(s),
 	}
-	if filter.Limit < 1 {
-		filter.Limit = 10
+}
+
+// SetNameKeys пересчитывает ключи поиска по текущим имени, фамилии и отчеству
+func (p *Person) SetNameKeys() {
+	p.NameKeys = NewNameKeys(p.Name)
+	p.SurnameKeys = NewNameKeys(p.Surname)
+	p.PatronymicKeys = NameKeys{}
+	if p.Patronymic != nil {
+		p.PatronymicKeys = NewNameKeys(*p.Patronymic)
 	}
-	offset := (filter.Page - 1) * filter.Limit
+}
 
-	// Получаем данные
-	if err := query.Order("created_at DESC").
-		Offset(offset).
-		Limit(filter.Limit).
-		Find(&people).Error; err != nil {
-		return nil, 0, fmt.Errorf("ошибка получения списка: %w", err)
+// PersonFields поля человека, которые задает клиент; PUT заменяет их
+// целиком, а PATCH применяется к их JSON-представлению
+type PersonFields struct {
+	Name        string  `json:"name"`
+	Surname     string  `json:"surname"`
+	Patronymic  *string `json:"patronymic"`
+	Age         int     `json:"age"`
+	Gender      string  `json:"gender"`
+	Nationality string  `json:"nationality"`
+	ExternalID  *string `json:"external_id"`
+}
+
+// Fields возвращает задаваемые клиентом поля
+func (p *Person) Fields() PersonFields {
+	return PersonFields{
+		Name:        p.Name,
+		Surname:     p.Surname,
+		Patronymic:  p.Patronymic,
+		Age:         p.Age,
+		Gender:      p.Gender,
+		Nationality: p.Nationality,
+		ExternalID:  p.ExternalID,
 	}
+}
 
-	return people, total, nil
+// SetFields заменяет задаваемые клиентом поля, сохраняя ID, версию
+// и временные метки
+func (p *Person) SetFields(f PersonFields) {
+	p.Name = f.Name
+	p.Surname = f.Surname
+	p.Patronymic = f.Patronymic
+	p.Age = f.Age
+	p.Gender = f.Gender
+	p.Nationality = f.Nationality
+	p.ExternalID = f.ExternalID
 }
 
+var (
+	ErrNameRequired    = errors.New("имя обязательно")
+	ErrSurnameRequired = errors.New("фамилия обязательна")
+	ErrInvalidName     = errors.New("имя может содержать только буквы")
+	ErrInvalidAge      = errors.New("возраст должен быть от 0 до 120")
+	ErrInvalidGender   = errors.New("пол должен быть 'male', 'female' или 'other'")
+	nameRegex          = regexp.MustCompile(`^[a-zA-Zа-яА-Я\-]+$`)
+)
+
 func (p *Person) Validate() error {
 	var errs []error
 
@@ -111,32 +155,46 @@ func (p *Person) Validate() error {
 	return nil
 }
 
-func (p *Person) Enrich() error {
+func (p *Person) Enrich(provider enrich.Provider) error {
 	if err := p.Validate(); err != nil {
 		return fmt.Errorf("нельзя обогатить невалидные данные: %w", err)
 	}
 
-	age, err := enrich.GetAge(p.Name)
+	result, err := enrich.Preview(provider, p.Query())
 	if err != nil {
-		return fmt.Errorf("ошибка получения возраста: %w", err)
+		return fmt.Errorf("ошибка получения предсказаний: %w", err)
 	}
-	p.Age = age
 
-	gender, err := enrich.GetGender(p.Name)
-	if err != nil {
-		return fmt.Errorf("ошибка определения пола: %w", err)
+	age := 0
+	if !result.Age.Empty() {
+		if age, err = strconv.Atoi(result.Age.Value); err != nil {
+			return fmt.Errorf("ошибка получения возраста: %w", err)
+		}
+	}
+	p.Age = age
+	p.Gender = result.Gender.Value
+	p.Nationality = "unknown"
+	if !result.Nationality.Empty() {
+		p.Nationality = result.Nationality.Value
 	}
-	p.Gender = gender
 
-	nationality, err := enrich.GetNationality(p.Name)
+	data, err := json.Marshal(result)
 	if err != nil {
-		return fmt.Errorf("ошибка определения национальности: %w", err)
+		return err
 	}
-	p.Nationality = nationality
-
+	p.Enrichment = RawJSON(data)
 	return nil
 }
 
+// Query возвращает части имени для источников обогащения
+func (p *Person) Query() enrich.Query {
+	q := enrich.Query{Name: p.Name, Surname: p.Surname}
+	if p.Patronymic != nil {
+		q.Patronymic = *p.Patronymic
+	}
+	return q
+}
+
 func contains(slice []string, item string) bool {
 	for _, s := range slice {
 		if s == item {
@@ -1,8 +1,25 @@
 package models
 
+import "people-service/internal/enrich"
+
 type PeopleListResponse struct {
-	Data  []Person `json:"data"`
-	Total int64    `json:"total"`
-	Page  int      `json:"page"`
-	Limit int      `json:"limit"`
+	Data       []Person `json:"data"`
+	Total      *int64   `json:"total,omitempty"`       // Не возвращается при skip_count=true
+	TotalPages *int64   `json:"total_pages,omitempty"` // Не возвращается при skip_count=true
+	Page       int      `json:"page,omitempty"`        // Не возвращается при постраничном выводе по курсору
+	Limit      int      `json:"limit"`                 // Фактический лимит с учетом максимума сервера
+	HasNext    bool     `json:"has_next"`
+	NextCursor string   `json:"next_cursor,omitempty"`
+	PrevCursor string   `json:"prev_cursor,omitempty"`
+}
+
+// SetTotal заполняет общее число записей и страниц
+func (r *PeopleListResponse) SetTotal(total int64) {
+	pages := (total + int64(r.Limit) - 1) / int64(r.Limit)
+	r.Total, r.TotalPages = &total, &pages
+}
+
+type EnrichmentPreviewResponse struct {
+	Query enrich.Query `json:"query"`
+	enrich.Result
 }
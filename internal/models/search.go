@@ -0,0 +1,65 @@
+package models
+
+import "strings"
+
+// Режимы поиска в SearchQuery.Mode
+const (
+	SearchFullText = "fts"     // Полнотекстовый поиск по словам
+	SearchTrigram  = "trigram" // Нечеткий поиск по триграммному сходству
+)
+
+// Конфигурации полнотекстового поиска Postgres в SearchQuery.Config
+const (
+	SearchConfigSimple  = "simple"  // Слова без изменений
+	SearchConfigRussian = "russian" // Слова приводятся к основе, например "Ивановой" -> "иванов"
+)
+
+// Теги, которыми выделяются совпадения в SearchResult.Highlight
+const (
+	HighlightStart = "<mark>"
+	HighlightStop  = "</mark>"
+)
+
+// SearchQuery параметры поиска по имени, фамилии и отчеству сразу
+type SearchQuery struct {
+	Q      string `form:"q" validate:"required,max=200"`
+	Mode   string `form:"mode" validate:"omitempty,oneof=fts trigram"`
+	Config string `form:"config" validate:"omitempty,oneof=simple russian"`
+	Limit  int    `form:"limit" validate:"omitempty,min=1"`
+}
+
+// Normalize убирает пробелы по краям запроса, заполняет значения по
+// умолчанию и ограничивает лимит сверху maxLimit, как PersonFilter.Paginate
+func (q *SearchQuery) Normalize(defaultLimit, maxLimit int) {
+	q.Q = strings.TrimSpace(q.Q)
+	if q.Mode == "" {
+		q.Mode = SearchFullText
+	}
+	if q.Config == "" {
+		q.Config = SearchConfigSimple
+	}
+	if q.Limit < 1 {
+		q.Limit = defaultLimit
+	}
+	if q.Limit < 1 {
+		q.Limit = 10
+	}
+	if maxLimit > 0 && q.Limit > maxLimit {
+		q.Limit = maxLimit
+	}
+}
+
+// SearchResult найденный человек с оценкой релевантности
+type SearchResult struct {
+	Person
+	Rank float64 `json:"rank" gorm:"column:search_rank"`
+	// Highlight полное имя, в котором совпадения обернуты в <mark>
+	Highlight string `json:"highlight" gorm:"column:search_highlight"`
+}
+
+type SearchResponse struct {
+	Data   []SearchResult `json:"data"`
+	Mode   string         `json:"mode"`
+	Config string         `json:"config,omitempty"` // Только для mode=fts
+	Limit  int            `json:"limit"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// DefaultSort порядок списка людей, если параметр sort не задан
+const DefaultSort = "-created_at"
+
+// SortableFields поля, по которым можно сортировать список людей
+var SortableFields = []string{
+	"id", "name", "surname", "patronymic", "age", "gender", "nationality", "created_at", "updated_at",
+}
+
+// SortField поле сортировки и ее направление
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// ParseSort разбирает список полей через запятую; минус перед полем задает
+// убывание. Если id не указан, он добавляется последним с направлением
+// последнего поля, чтобы порядок был однозначным
+func ParseSort(s string) ([]SortField, error) {
+	if strings.TrimSpace(s) == "" {
+		s = DefaultSort
+	}
+
+	var fields []SortField
+	seen := make(map[string]bool)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		field := SortField{Field: strings.TrimPrefix(item, "-"), Desc: strings.HasPrefix(item, "-")}
+		if !slices.Contains(SortableFields, field.Field) {
+			return nil, fmt.Errorf("unknown sort field %q, allowed: %s", field.Field, strings.Join(SortableFields, ", "))
+		}
+		if seen[field.Field] {
+			return nil, fmt.Errorf("sort field %q is repeated", field.Field)
+		}
+		seen[field.Field] = true
+		fields = append(fields, field)
+	}
+
+	if !seen["id"] {
+		fields = append(fields, SortField{Field: "id", Desc: fields[len(fields)-1].Desc})
+	}
+	return fields, nil
+}
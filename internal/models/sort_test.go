@@ -0,0 +1,100 @@
+package models_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"people-service/internal/models"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []models.SortField
+		wantErr bool
+	}{
+		{"", []models.SortField{{Field: "created_at", Desc: true}, {Field: "id", Desc: true}}, false},
+		{"surname,-age", []models.SortField{{Field: "surname"}, {Field: "age", Desc: true}, {Field: "id", Desc: true}}, false},
+		{"-id,name", []models.SortField{{Field: "id", Desc: true}, {Field: "name"}}, false},
+		{"height", nil, true},
+		{"name,-name", nil, true},
+		{"name,", nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := models.ParseSort(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSort(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseSort(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCursorKey(t *testing.T) {
+	patronymic := "Ivanovich"
+	person := models.Person{
+		Name:        "Ivan",
+		Surname:     "Petrov",
+		Patronymic:  &patronymic,
+		Age:         42,
+		Gender:      "male",
+		Nationality: "RU",
+	}
+	person.ID = 7
+	person.CreatedAt = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	bySurname, _ := models.ParseSort("surname")
+	byCreated, _ := models.ParseSort("-created_at")
+
+	tests := []struct {
+		name    string
+		sort    []models.SortField
+		want    []string
+		wantOff []string
+	}{
+		{"surname", bySurname, []string{`"surname":"Petrov"`, `"id":7`}, []string{"Ivan\"", "Ivanovich", "42", "male", "RU", "created_at"}},
+		{"created_at", byCreated, []string{`"created_at":"2024-01-02T03:04:05Z"`, `"id":7`}, []string{"Ivan", "Petrov", "42", "male", "RU"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded := models.NewCursor(person, tt.sort, false).Encode()
+			data, err := base64.RawURLEncoding.DecodeString(encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(string(data), s) {
+					t.Errorf("cursor %s does not contain %s", data, s)
+				}
+			}
+			for _, s := range tt.wantOff {
+				if strings.Contains(string(data), s) {
+					t.Errorf("cursor %s leaks %s", data, s)
+				}
+			}
+
+			c, err := models.DecodeCursor(encoded, tt.sort)
+			if err != nil {
+				t.Fatalf("DecodeCursor() error = %v", err)
+			}
+			if c.Key.ID != person.ID {
+				t.Errorf("Key.ID = %d, want %d", c.Key.ID, person.ID)
+			}
+		})
+	}
+
+	if _, err := models.DecodeCursor(models.NewCursor(person, bySurname, false).Encode(), byCreated); !errors.Is(err, models.ErrInvalidCursor) {
+		t.Errorf("cursor for another sort: error = %v, want ErrInvalidCursor", err)
+	}
+	forged := base64.RawURLEncoding.EncodeToString([]byte(`{"s":"surname,id","k":{"id":7,"surname":"Petrov","name":"Ivan"}}`))
+	if _, err := models.DecodeCursor(forged, bySurname); !errors.Is(err, models.ErrInvalidCursor) {
+		t.Errorf("cursor with a field outside the sort: error = %v, want ErrInvalidCursor", err)
+	}
+}
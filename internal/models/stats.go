@@ -0,0 +1,65 @@
+package models
+
+// DefaultBucketWidth ширина возрастной группы по умолчанию, лет
+const DefaultBucketWidth = 10
+
+// StatsQuery параметры статистики, дополняющие PersonFilter
+type StatsQuery struct {
+	BucketWidth int `form:"bucket_width" validate:"omitempty,min=1,max=120"`
+}
+
+// Percentiles уровни процентилей возраста в AgeStats
+var Percentiles = []float64{0.25, 0.5, 0.75, 0.9, 0.99}
+
+// CountBucket число записей с одним значением поля; пустое значение —
+// поле не заполнено
+type CountBucket struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// AgeBucket число записей с возрастом в [From, To)
+type AgeBucket struct {
+	From  int   `json:"from"`
+	To    int   `json:"to"`
+	Count int64 `json:"count"`
+}
+
+// AgeStats сводка по возрасту; значения пусты, если записей с известным
+// возрастом нет. Возраст 0 означает, что он не определен: такие записи
+// не входят в среднее, процентили и возрастные группы, а считаются в Unknown.
+// Процентили считаются с линейной интерполяцией, как percentile_cont
+type AgeStats struct {
+	Unknown int64    `json:"unknown"`
+	Average *float64 `json:"average"`
+	Min     *int     `json:"min"`
+	Max     *int     `json:"max"`
+	P25     *float64 `json:"p25"`
+	Median  *float64 `json:"median"`
+	P75     *float64 `json:"p75"`
+	P90     *float64 `json:"p90"`
+	P99     *float64 `json:"p99"`
+}
+
+// SetPercentiles заполняет процентили значениями в порядке Percentiles
+func (s *AgeStats) SetPercentiles(values []float64) {
+	targets := []**float64{&s.P25, &s.Median, &s.P75, &s.P90, &s.P99}
+	for i := range targets {
+		if i < len(values) {
+			value := values[i]
+			*targets[i] = &value
+		}
+	}
+}
+
+// PeopleStats распределения записей, подходящих под фильтр. Группы по полу
+// и национальности идут от самых многочисленных, возрастные группы — по
+// возрастанию без пропусков между минимальным и максимальным возрастом
+type PeopleStats struct {
+	Total       int64         `json:"total"`
+	Gender      []CountBucket `json:"gender"`
+	Nationality []CountBucket `json:"nationality"`
+	BucketWidth int           `json:"bucket_width"`
+	AgeBuckets  []AgeBucket   `json:"age_buckets"`
+	Age         AgeStats      `json:"age"`
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// VersionOperation вид изменения, записанного в историю
+type VersionOperation string
+
+const (
+	VersionCreate  VersionOperation = "create"
+	VersionUpdate  VersionOperation = "update"
+	VersionDelete  VersionOperation = "delete"
+	VersionRestore VersionOperation = "restore"
+	VersionMerge   VersionOperation = "merge"
+	VersionRevert  VersionOperation = "revert"
+)
+
+// PersonVersion запись истории изменений человека
+type PersonVersion struct {
+	ID        uint             `json:"id" gorm:"primaryKey"`
+	PersonID  uint             `json:"person_id"`
+	Version   int              `json:"version"` // Person.Version после изменения, то есть ETag записи
+	Operation VersionOperation `json:"operation"`
+	Snapshot  RawJSON          `json:"snapshot"` // Запись после изменения
+	Diff      RawJSON          `json:"diff"`     // Измененные поля: имя -> {old, new}
+	Actor     string           `json:"actor,omitempty"`
+	RequestID string           `json:"request_id,omitempty"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+// FieldChange старое и новое значение поля
+type FieldChange struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+// Person восстанавливает запись из снимка версии
+func (v PersonVersion) Person() (Person, error) {
+	var person Person
+	err := json.Unmarshal([]byte(v.Snapshot), &person)
+	return person, err
+}
+
+// DiffPeople возвращает изменившиеся пользовательские поля; before == nil
+// означает создание записи
+func DiffPeople(before, after *Person) map[string]FieldChange {
+	if before == nil {
+		before = &Person{}
+	}
+	changes := make(map[string]FieldChange)
+	compare := func(field string, old, new any) {
+		if old != new {
+			changes[field] = FieldChange{Old: old, New: new}
+		}
+	}
+
+	compare("name", before.Name, after.Name)
+	compare("surname", before.Surname, after.Surname)
+	compare("patronymic", stringOrNil(before.Patronymic), stringOrNil(after.Patronymic))
+	compare("age", before.Age, after.Age)
+	compare("gender", before.Gender, after.Gender)
+	compare("nationality", before.Nationality, after.Nationality)
+	compare("external_id", stringOrNil(before.ExternalID), stringOrNil(after.ExternalID))
+	return changes
+}
+
+func stringOrNil(s *string) any {
+	if s == nil {
+		return nil
+	}
+	return *s
+}
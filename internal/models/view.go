@@ -0,0 +1,100 @@
+package models
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+)
+
+// Вложения, которые можно запросить в ViewQuery.Expand
+const (
+	ExpandEnrichment = "enrichment" // Предсказания, сохраненные при создании записи
+	ExpandHistory    = "history"    // Версии записи от первой к последней
+)
+
+// viewFields имена полей в fields= и соответствующие им ключи JSON Person
+var viewFields = map[string]string{
+	"id":          "ID",
+	"name":        "name",
+	"surname":     "surname",
+	"patronymic":  "patronymic",
+	"age":         "age",
+	"gender":      "gender",
+	"nationality": "nationality",
+	"external_id": "external_id",
+	"version":     "version",
+	"created_at":  "CreatedAt",
+	"updated_at":  "UpdatedAt",
+	"deleted_at":  "DeletedAt",
+}
+
+// ViewQuery выбор полей и вложений в ответах о людях; поля и вложения
+// принимают несколько значений через запятую или повтором параметра
+type ViewQuery struct {
+	Fields []string `form:"fields" validate:"dive,oneof=id name surname patronymic age gender nationality external_id version created_at updated_at deleted_at"`
+	Expand []string `form:"expand" validate:"dive,oneof=enrichment history"`
+}
+
+// Normalize разбивает списки через запятую и приводит их к нижнему регистру
+func (q *ViewQuery) Normalize() {
+	q.Fields = splitList(q.Fields, strings.ToLower)
+	q.Expand = splitList(q.Expand, strings.ToLower)
+}
+
+// Empty сообщает, что запрошено полное представление без вложений
+func (q ViewQuery) Empty() bool {
+	return len(q.Fields) == 0 && len(q.Expand) == 0
+}
+
+// Key возвращает каноническую запись выбора полей и вложений, не зависящую
+// от порядка и повторов; пусто для полного представления
+func (q ViewQuery) Key() string {
+	if q.Empty() {
+		return ""
+	}
+	return strings.Join(sortedSet(q.Fields), ",") + ";" + strings.Join(sortedSet(q.Expand), ",")
+}
+
+func sortedSet(values []string) []string {
+	set := slices.Clone(values)
+	slices.Sort(set)
+	return slices.Compact(set)
+}
+
+// PersonView JSON-объект человека с выбранными полями и вложениями
+type PersonView map[string]json.RawMessage
+
+// NewPersonView строит представление person только с полями fields
+// (все поля, если список пуст)
+func NewPersonView(person Person, fields []string) (PersonView, error) {
+	data, err := json.Marshal(person)
+	if err != nil {
+		return nil, err
+	}
+	var view PersonView
+	if err := json.Unmarshal(data, &view); err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return view, nil
+	}
+
+	selected := make(PersonView, len(fields))
+	for _, field := range fields {
+		key := viewFields[field]
+		if value, ok := view[key]; ok {
+			selected[key] = value
+		}
+	}
+	return selected, nil
+}
+
+// Set добавляет в представление вложение key
+func (v PersonView) Set(key string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	v[key] = data
+	return nil
+}
@@ -0,0 +1,68 @@
+// Package names нормализует и сравнивает имена людей независимо от алфавита
+package names
+
+import (
+	"strings"
+	"unicode"
+)
+
+// cyrillicToLatin упрощенная транслитерация, близкая к паспортной
+var cyrillicToLatin = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+	'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g",
+}
+
+// Transliterate переводит строку в нижний регистр и заменяет кириллицу латиницей
+func Transliterate(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if latin, ok := cyrillicToLatin[r]; ok {
+			b.WriteString(latin)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Normalize приводит имя к латинице в нижнем регистре, оставляя только буквы
+// и одиночные пробелы между словами
+func Normalize(s string) string {
+	fields := strings.FieldsFunc(Transliterate(s), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	return strings.Join(fields, " ")
+}
+
+// Trigrams возвращает множество триграмм нормализованной строки по правилам
+// pg_trgm: каждое слово дополняется двумя пробелами слева и одним справа
+func Trigrams(s string) map[string]struct{} {
+	set := make(map[string]struct{})
+	for _, word := range strings.Fields(Normalize(s)) {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			set[string(padded[i:i+3])] = struct{}{}
+		}
+	}
+	return set
+}
+
+// Similarity возвращает коэффициент Жаккара по триграммам, от 0 до 1
+func Similarity(a, b string) float64 {
+	ta, tb := Trigrams(a), Trigrams(b)
+	if len(ta) == 0 && len(tb) == 0 {
+		return 0
+	}
+
+	common := 0
+	for t := range ta {
+		if _, ok := tb[t]; ok {
+			common++
+		}
+	}
+	return float64(common) / float64(len(ta)+len(tb)-common)
+}
@@ -0,0 +1,38 @@
+package names_test
+
+import (
+	"people-service/internal/names"
+	"testing"
+)
+
+func TestTransliterate(t *testing.T) {
+	tests := map[string]string{
+		"Дмитрий":   "dmitriy",
+		"Щукин":     "shchukin",
+		"Ushakov":   "ushakov",
+		"Юлия-Анна": "yuliya-anna",
+	}
+	for in, want := range tests {
+		if got := names.Transliterate(in); got != want {
+			t.Errorf("Transliterate(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		min, max float64
+	}{
+		{"Ushakov", "ushakov", 1, 1},
+		{"Дмитрий", "Dmitriy", 1, 1},
+		{"Dmitriy", "Dmitry", 0.4, 0.6},
+		{"Anna", "Boris", 0, 0},
+		{"", "", 0, 0},
+	}
+	for _, tt := range tests {
+		if got := names.Similarity(tt.a, tt.b); got < tt.min || got > tt.max {
+			t.Errorf("Similarity(%q, %q) = %.2f, want in [%.2f, %.2f]", tt.a, tt.b, got, tt.min, tt.max)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package patch_test
+
+import (
+	"encoding/json"
+	"errors"
+	"people-service/internal/patch"
+	"reflect"
+	"testing"
+)
+
+func equalJSON(t *testing.T, got []byte, want string) bool {
+	t.Helper()
+	var g, w any
+	if err := json.Unmarshal(got, &g); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(want), &w); err != nil {
+		t.Fatal(err)
+	}
+	return reflect.DeepEqual(g, w)
+}
+
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		doc, patch, want string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+	}
+
+	for _, tt := range tests {
+		got, err := patch.MergePatch([]byte(tt.doc), []byte(tt.patch))
+		if err != nil {
+			t.Errorf("MergePatch(%s, %s) error = %v", tt.doc, tt.patch, err)
+			continue
+		}
+		if !equalJSON(t, got, tt.want) {
+			t.Errorf("MergePatch(%s, %s) = %s, want %s", tt.doc, tt.patch, got, tt.want)
+		}
+	}
+}
+
+func TestJSONPatch(t *testing.T) {
+	tests := []struct {
+		name, doc, patch, want string
+		wantErr                error
+	}{
+		{"add member", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"foo":"bar","baz":"qux"}`, nil},
+		{"add array element", `{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`, nil},
+		{"append", `{"foo":[1]}`, `[{"op":"add","path":"/foo/-","value":2}]`, `{"foo":[1,2]}`, nil},
+		{"remove", `{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`, nil},
+		{"replace", `{"baz":"qux"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo"}`, nil},
+		{"move", `{"foo":{"bar":"baz"},"qux":{}}`, `[{"op":"move","from":"/foo/bar","path":"/qux/thud"}]`, `{"foo":{},"qux":{"thud":"baz"}}`, nil},
+		{"copy", `{"a":{"b":1}}`, `[{"op":"copy","from":"/a","path":"/c"}]`, `{"a":{"b":1},"c":{"b":1}}`, nil},
+		{"escaped pointer", `{"a/b":1,"m~n":2}`, `[{"op":"replace","path":"/a~1b","value":3},{"op":"remove","path":"/m~0n"}]`, `{"a/b":3}`, nil},
+		{"test passes", `{"age":30}`, `[{"op":"test","path":"/age","value":30},{"op":"replace","path":"/age","value":31}]`, `{"age":31}`, nil},
+		{"test fails", `{"age":30}`, `[{"op":"test","path":"/age","value":31}]`, "", patch.ErrTestFailed},
+		{"replace missing", `{}`, `[{"op":"replace","path":"/a","value":1}]`, "", patch.ErrInvalidPatch},
+		{"remove missing", `{}`, `[{"op":"remove","path":"/a"}]`, "", patch.ErrInvalidPatch},
+		{"unknown op", `{}`, `[{"op":"frob","path":"/a"}]`, "", patch.ErrInvalidPatch},
+		{"missing value", `{}`, `[{"op":"add","path":"/a"}]`, "", patch.ErrInvalidPatch},
+		{"bad index", `{"a":[1]}`, `[{"op":"add","path":"/a/5","value":1}]`, "", patch.ErrInvalidPatch},
+		{"not a list", `{}`, `{"op":"add"}`, "", patch.ErrInvalidPatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := patch.JSONPatch([]byte(tt.doc), []byte(tt.patch))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("JSONPatch() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("JSONPatch() error = %v", err)
+			}
+			if !equalJSON(t, got, tt.want) {
+				t.Errorf("JSONPatch() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
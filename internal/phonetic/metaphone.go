@@ -0,0 +1,544 @@
+// Package phonetic строит фонетические ключи имен: Double Metaphone для
+// латиницы и упрощенный русский Metaphone для кириллицы
+package phonetic
+
+import (
+	"strings"
+	"unicode"
+)
+
+// metaphoneLength длина ключа Double Metaphone, как в оригинальном алгоритме
+const metaphoneLength = 4
+
+// DoubleMetaphone возвращает основной и альтернативный ключи Double Metaphone
+// (Lawrence Philips) для латинской строки; прочие символы пропускаются
+func DoubleMetaphone(s string) (primary, alternate string) {
+	m := newMetaphone(s)
+	if len(m.value) == 0 {
+		return "", ""
+	}
+	m.encode()
+	return m.primary.String(), m.alternate.String()
+}
+
+type metaphone struct {
+	value              []rune
+	slavoGermanic      bool
+	primary, alternate strings.Builder
+}
+
+func newMetaphone(s string) *metaphone {
+	var value []rune
+	for _, r := range strings.ToUpper(strings.TrimSpace(s)) {
+		if unicode.IsLetter(r) || r == ' ' {
+			value = append(value, r)
+		}
+	}
+	upper := string(value)
+	return &metaphone{
+		value: value,
+		slavoGermanic: strings.ContainsAny(upper, "WK") ||
+			strings.Contains(upper, "CZ") || strings.Contains(upper, "WITZ"),
+	}
+}
+
+// at возвращает символ по индексу или 0 за границами строки
+func (m *metaphone) at(i int) rune {
+	if i < 0 || i >= len(m.value) {
+		return 0
+	}
+	return m.value[i]
+}
+
+func (m *metaphone) last() int {
+	return len(m.value) - 1
+}
+
+// contains сообщает, совпадает ли подстрока длины n с позиции start
+// с одним из вариантов
+func (m *metaphone) contains(start, n int, variants ...string) bool {
+	if start < 0 || start+n > len(m.value) {
+		return false
+	}
+	sub := string(m.value[start : start+n])
+	for _, v := range variants {
+		if sub == v {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *metaphone) isVowel(i int) bool {
+	return strings.ContainsRune("AEIOUY", m.at(i))
+}
+
+func (m *metaphone) complete() bool {
+	return m.primary.Len() >= metaphoneLength && m.alternate.Len() >= metaphoneLength
+}
+
+func appendLimited(b *strings.Builder, s string) {
+	if free := metaphoneLength - b.Len(); free > 0 {
+		if len(s) > free {
+			s = s[:free]
+		}
+		b.WriteString(s)
+	}
+}
+
+// add дописывает код к обоим ключам или, если задан alt, разные коды
+func (m *metaphone) add(primary string, alt ...string) {
+	appendLimited(&m.primary, primary)
+	if len(alt) > 0 {
+		appendLimited(&m.alternate, alt[0])
+	} else {
+		appendLimited(&m.alternate, primary)
+	}
+}
+
+func (m *metaphone) addPrimary(s string) {
+	appendLimited(&m.primary, s)
+}
+
+func (m *metaphone) addAlternate(s string) {
+	appendLimited(&m.alternate, s)
+}
+
+// skip возвращает i+2, если следующий символ совпадает с одним из same, иначе i+1
+func (m *metaphone) skip(i int, same ...string) int {
+	if m.contains(i+1, 1, same...) {
+		return i + 2
+	}
+	return i + 1
+}
+
+func (m *metaphone) encode() {
+	i := 0
+	if m.contains(0, 2, "GN", "KN", "PN", "WR", "PS") {
+		i = 1
+	}
+	if m.at(0) == 'X' {
+		m.add("S")
+		i = 1
+	}
+
+	for !m.complete() && i <= m.last() {
+		switch m.at(i) {
+		case 'A', 'E', 'I', 'O', 'U', 'Y':
+			if i == 0 {
+				m.add("A")
+			}
+			i++
+		case 'B':
+			m.add("P")
+			i = m.skip(i, "B")
+		case 'Ç':
+			m.add("S")
+			i++
+		case 'C':
+			i = m.c(i)
+		case 'D':
+			i = m.d(i)
+		case 'F':
+			m.add("F")
+			i = m.skip(i, "F")
+		case 'G':
+			i = m.g(i)
+		case 'H':
+			i = m.h(i)
+		case 'J':
+			i = m.j(i)
+		case 'K':
+			m.add("K")
+			i = m.skip(i, "K")
+		case 'L':
+			i = m.l(i)
+		case 'M':
+			m.add("M")
+			if m.at(i+1) == 'M' || m.contains(i-1, 3, "UMB") && (i+1 == m.last() || m.contains(i+2, 2, "ER")) {
+				i += 2
+			} else {
+				i++
+			}
+		case 'N':
+			m.add("N")
+			i = m.skip(i, "N")
+		case 'Ñ':
+			m.add("N")
+			i++
+		case 'P':
+			if m.at(i+1) == 'H' {
+				m.add("F")
+				i += 2
+			} else {
+				m.add("P")
+				i = m.skip(i, "P", "B")
+			}
+		case 'Q':
+			m.add("K")
+			i = m.skip(i, "Q")
+		case 'R':
+			if i == m.last() && !m.slavoGermanic && m.contains(i-2, 2, "IE") && !m.contains(i-4, 2, "ME", "MA") {
+				m.addAlternate("R")
+			} else {
+				m.add("R")
+			}
+			i = m.skip(i, "R")
+		case 'S':
+			i = m.s(i)
+		case 'T':
+			i = m.t(i)
+		case 'V':
+			m.add("F")
+			i = m.skip(i, "V")
+		case 'W':
+			i = m.w(i)
+		case 'X':
+			i = m.x(i)
+		case 'Z':
+			i = m.z(i)
+		default:
+			i++
+		}
+	}
+}
+
+func (m *metaphone) c(i int) int {
+	switch {
+	case m.germanicC(i):
+		m.add("K")
+		return i + 2
+	case i == 0 && m.contains(i, 6, "CAESAR"):
+		m.add("S")
+		return i + 2
+	case m.contains(i, 2, "CH"):
+		return m.ch(i)
+	case m.contains(i, 2, "CZ") && !m.contains(i-2, 4, "WICZ"):
+		m.add("S", "X")
+		return i + 2
+	case m.contains(i+1, 3, "CIA"):
+		m.add("X")
+		return i + 3
+	case m.contains(i, 2, "CC") && !(i == 1 && m.at(0) == 'M'):
+		if m.contains(i+2, 1, "I", "E", "H") && !m.contains(i+2, 2, "HU") {
+			if i == 1 && m.at(0) == 'A' || m.contains(i-1, 5, "UCCEE", "UCCES") {
+				m.add("KS")
+			} else {
+				m.add("X")
+			}
+			return i + 3
+		}
+		m.add("K")
+		return i + 2
+	case m.contains(i, 2, "CK", "CG", "CQ"):
+		m.add("K")
+		return i + 2
+	case m.contains(i, 2, "CI", "CE", "CY"):
+		if m.contains(i, 3, "CIO", "CIE", "CIA") {
+			m.add("S", "X")
+		} else {
+			m.add("S")
+		}
+		return i + 2
+	}
+
+	m.add("K")
+	switch {
+	case m.contains(i+1, 2, " C", " Q", " G"):
+		return i + 3
+	case m.contains(i+1, 1, "C", "K", "Q") && !m.contains(i+1, 2, "CE", "CI"):
+		return i + 2
+	}
+	return i + 1
+}
+
+// germanicC сочетания вроде BACHER и MACHER, где CH читается как K
+func (m *metaphone) germanicC(i int) bool {
+	if m.contains(i, 4, "CHIA") {
+		return true
+	}
+	if i <= 1 || m.isVowel(i-2) || !m.contains(i-1, 3, "ACH") {
+		return false
+	}
+	next := m.at(i + 2)
+	return next != 'I' && next != 'E' || m.contains(i-2, 6, "BACHER", "MACHER")
+}
+
+func (m *metaphone) ch(i int) int {
+	switch {
+	case i > 0 && m.contains(i, 4, "CHAE"):
+		m.add("K", "X")
+	case m.greekCH(i) || m.germanicCH(i):
+		m.add("K")
+	case i > 0 && m.contains(0, 2, "MC"):
+		m.add("K")
+	case i > 0:
+		m.add("X", "K")
+	default:
+		m.add("X")
+	}
+	return i + 2
+}
+
+func (m *metaphone) greekCH(i int) bool {
+	return i == 0 &&
+		(m.contains(i+1, 5, "HARAC", "HARIS") || m.contains(i+1, 3, "HOR", "HYM", "HIA", "HEM")) &&
+		!m.contains(0, 5, "CHORE")
+}
+
+func (m *metaphone) germanicCH(i int) bool {
+	return m.contains(0, 4, "VAN ", "VON ") || m.contains(0, 3, "SCH") ||
+		m.contains(i-2, 6, "ORCHES", "ARCHIT", "ORCHID") || m.contains(i+2, 1, "T", "S") ||
+		(m.contains(i-1, 1, "A", "O", "U", "E") || i == 0) &&
+			(m.contains(i+2, 1, "L", "R", "N", "M", "B", "H", "F", "V", "W", " ") || i+1 == m.last())
+}
+
+func (m *metaphone) d(i int) int {
+	switch {
+	case m.contains(i, 2, "DG"):
+		if m.contains(i+2, 1, "I", "E", "Y") {
+			m.add("J")
+			return i + 3
+		}
+		m.add("TK")
+		return i + 2
+	case m.contains(i, 2, "DT", "DD"):
+		m.add("T")
+		return i + 2
+	}
+	m.add("T")
+	return i + 1
+}
+
+func (m *metaphone) g(i int) int {
+	switch {
+	case m.at(i+1) == 'H':
+		return m.gh(i)
+	case m.at(i+1) == 'N':
+		switch {
+		case i == 1 && m.isVowel(0) && !m.slavoGermanic:
+			m.add("KN", "N")
+		case !m.contains(i+2, 2, "EY") && m.at(i+1) != 'Y' && !m.slavoGermanic:
+			m.add("N", "KN")
+		default:
+			m.add("KN")
+		}
+		return i + 2
+	case m.contains(i+1, 2, "LI") && !m.slavoGermanic:
+		m.add("KL", "L")
+		return i + 2
+	case i == 0 && (m.at(i+1) == 'Y' ||
+		m.contains(i+1, 2, "ES", "EP", "EB", "EL", "EY", "IB", "IL", "IN", "IE", "EI", "ER")):
+		m.add("K", "J")
+		return i + 2
+	case (m.contains(i+1, 2, "ER") || m.at(i+1) == 'Y') &&
+		!m.contains(0, 6, "DANGER", "RANGER", "MANGER") &&
+		!m.contains(i-1, 1, "E", "I") && !m.contains(i-1, 3, "RGY", "OGY"):
+		m.add("K", "J")
+		return i + 2
+	case m.contains(i+1, 1, "E", "I", "Y") || m.contains(i-1, 4, "AGGI", "OGGI"):
+		switch {
+		case m.contains(0, 4, "VAN ", "VON ") || m.contains(0, 3, "SCH") || m.contains(i+1, 2, "ET"):
+			m.add("K")
+		case m.contains(i+1, 3, "IER"):
+			m.add("J")
+		default:
+			m.add("J", "K")
+		}
+		return i + 2
+	}
+	m.add("K")
+	return m.skip(i, "G")
+}
+
+func (m *metaphone) gh(i int) int {
+	switch {
+	case i > 0 && !m.isVowel(i-1):
+		m.add("K")
+	case i == 0:
+		if m.at(i+2) == 'I' {
+			m.add("J")
+		} else {
+			m.add("K")
+		}
+	case i > 1 && m.contains(i-2, 1, "B", "H", "D") ||
+		i > 2 && m.contains(i-3, 1, "B", "H", "D") ||
+		i > 3 && m.contains(i-4, 1, "B", "H"):
+		// GH в словах вроде bough и broughton не произносится
+	case i > 2 && m.at(i-1) == 'U' && m.contains(i-3, 1, "C", "G", "L", "R", "T"):
+		m.add("F")
+	case m.at(i-1) != 'I':
+		m.add("K")
+	}
+	return i + 2
+}
+
+func (m *metaphone) h(i int) int {
+	if (i == 0 || m.isVowel(i-1)) && m.isVowel(i+1) {
+		m.add("H")
+		return i + 2
+	}
+	return i + 1
+}
+
+func (m *metaphone) j(i int) int {
+	if m.contains(i, 4, "JOSE") || m.contains(0, 4, "SAN ") {
+		if i == 0 && m.at(i+4) == ' ' || len(m.value) == 4 || m.contains(0, 4, "SAN ") {
+			m.add("H")
+		} else {
+			m.add("J", "H")
+		}
+		return i + 1
+	}
+
+	switch {
+	case i == 0:
+		m.add("J", "A")
+	case m.isVowel(i-1) && !m.slavoGermanic && (m.at(i+1) == 'A' || m.at(i+1) == 'O'):
+		m.add("J", "H")
+	case i == m.last():
+		m.addPrimary("J")
+	case !m.contains(i+1, 1, "L", "T", "K", "S", "N", "M", "B", "Z") && !m.contains(i-1, 1, "S", "K", "L"):
+		m.add("J")
+	}
+	return m.skip(i, "J")
+}
+
+func (m *metaphone) l(i int) int {
+	if m.at(i+1) != 'L' {
+		m.add("L")
+		return i + 1
+	}
+	// Испанское LL в словах вроде cabrillo и gallegos
+	spanish := i == len(m.value)-3 && m.contains(i-1, 4, "ILLO", "ILLA", "ALLE") ||
+		(m.contains(len(m.value)-2, 2, "AS", "OS") || m.contains(m.last(), 1, "A", "O")) &&
+			m.contains(i-1, 4, "ALLE")
+	if spanish {
+		m.addPrimary("L")
+	} else {
+		m.add("L")
+	}
+	return i + 2
+}
+
+func (m *metaphone) s(i int) int {
+	switch {
+	case m.contains(i-1, 3, "ISL", "YSL"):
+		return i + 1
+	case i == 0 && m.contains(i, 5, "SUGAR"):
+		m.add("X", "S")
+		return i + 1
+	case m.contains(i, 2, "SH"):
+		if m.contains(i+1, 4, "HEIM", "HOEK", "HOLM", "HOLZ") {
+			m.add("S")
+		} else {
+			m.add("X")
+		}
+		return i + 2
+	case m.contains(i, 3, "SIO", "SIA") || m.contains(i, 4, "SIAN"):
+		if m.slavoGermanic {
+			m.add("S")
+		} else {
+			m.add("S", "X")
+		}
+		return i + 3
+	case i == 0 && m.contains(i+1, 1, "M", "N", "L", "W") || m.contains(i+1, 1, "Z"):
+		m.add("S", "X")
+		return m.skip(i, "Z")
+	case m.contains(i, 2, "SC"):
+		return m.sc(i)
+	}
+
+	if i == m.last() && m.contains(i-2, 2, "AI", "OI") {
+		m.addAlternate("S")
+	} else {
+		m.add("S")
+	}
+	return m.skip(i, "S", "Z")
+}
+
+func (m *metaphone) sc(i int) int {
+	switch {
+	case m.at(i+2) == 'H':
+		switch {
+		case m.contains(i+3, 2, "ER", "EN"):
+			m.add("X", "SK")
+		case m.contains(i+3, 2, "OO", "UY", "ED", "EM"):
+			m.add("SK")
+		case i == 0 && !m.isVowel(3) && m.at(3) != 'W':
+			m.add("X", "S")
+		default:
+			m.add("X")
+		}
+	case m.contains(i+2, 1, "I", "E", "Y"):
+		m.add("S")
+	default:
+		m.add("SK")
+	}
+	return i + 3
+}
+
+func (m *metaphone) t(i int) int {
+	switch {
+	case m.contains(i, 4, "TION") || m.contains(i, 3, "TIA", "TCH"):
+		m.add("X")
+		return i + 3
+	case m.contains(i, 2, "TH") || m.contains(i, 3, "TTH"):
+		if m.contains(i+2, 2, "OM", "AM") || m.contains(0, 4, "VAN ", "VON ") || m.contains(0, 3, "SCH") {
+			m.add("T")
+		} else {
+			m.add("0", "T")
+		}
+		return i + 2
+	}
+	m.add("T")
+	return m.skip(i, "T", "D")
+}
+
+func (m *metaphone) w(i int) int {
+	switch {
+	case m.contains(i, 2, "WR"):
+		m.add("R")
+		return i + 2
+	case i == 0 && (m.isVowel(i+1) || m.contains(i, 2, "WH")):
+		if m.isVowel(i + 1) {
+			m.add("A", "F")
+		} else {
+			m.add("A")
+		}
+		return i + 1
+	case i == m.last() && m.isVowel(i-1) ||
+		m.contains(i-1, 5, "EWSKI", "EWSKY", "OWSKI", "OWSKY") || m.contains(0, 3, "SCH"):
+		m.addAlternate("F")
+		return i + 1
+	case m.contains(i, 4, "WICZ", "WITZ"):
+		m.add("TS", "FX")
+		return i + 4
+	}
+	return i + 1
+}
+
+func (m *metaphone) x(i int) int {
+	if i == 0 {
+		m.add("S")
+		return i + 1
+	}
+	// Французское X на конце, как в breaux, не произносится
+	if !(i == m.last() && (m.contains(i-3, 3, "IAU", "EAU") || m.contains(i-2, 2, "AU", "OU"))) {
+		m.add("KS")
+	}
+	return m.skip(i, "C", "X")
+}
+
+func (m *metaphone) z(i int) int {
+	if m.at(i+1) == 'H' {
+		m.add("J")
+		return i + 2
+	}
+	if m.contains(i+1, 2, "ZO", "ZI", "ZA") || m.slavoGermanic && i > 0 && m.at(i-1) != 'T' {
+		m.add("S", "TS")
+	} else {
+		m.add("S")
+	}
+	return m.skip(i, "Z")
+}
@@ -0,0 +1,45 @@
+package phonetic_test
+
+import (
+	"people-service/internal/phonetic"
+	"testing"
+)
+
+func TestDoubleMetaphone(t *testing.T) {
+	tests := []struct {
+		in, primary, alternate string
+	}{
+		{"Dmitriy", "TMTR", "TMTR"},
+		{"Dmitry", "TMTR", "TMTR"},
+		{"Smith", "SM0", "XMT"},
+		{"Schmidt", "XMT", "SMT"},
+		{"Thompson", "TMPS", "TMPS"},
+		{"Knight", "NT", "NT"},
+		{"Xavier", "SF", "SFR"},
+		{"Caesar", "SSR", "SSR"},
+		{"Jose", "HS", "HS"},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		primary, alternate := phonetic.DoubleMetaphone(tt.in)
+		if primary != tt.primary || alternate != tt.alternate {
+			t.Errorf("DoubleMetaphone(%q) = %q, %q, want %q, %q", tt.in, primary, alternate, tt.primary, tt.alternate)
+		}
+	}
+}
+
+func TestRussian(t *testing.T) {
+	tests := map[string]string{
+		"Дмитрий":   "ДМИТРИ",
+		"Дмитрей":   "ДМИТРИ",
+		"Иванов":    "ИВАНАФ",
+		"Иваноф":    "ИВАНАФ",
+		"Сергеевич": "СИРГИВИЧ",
+		"Dmitry":    "",
+	}
+	for in, want := range tests {
+		if got := phonetic.Russian(in); got != want {
+			t.Errorf("Russian(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
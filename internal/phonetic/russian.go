@@ -0,0 +1,70 @@
+package phonetic
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Замены гласных русского Metaphone; сочетания проверяются раньше одиночных букв
+var (
+	russianVowelPairs = strings.NewReplacer("ЙО", "И", "ИО", "И", "ЙЕ", "И", "ИЕ", "И")
+	russianVowels     = map[rune]rune{
+		'О': 'А', 'Ы': 'А', 'Я': 'А',
+		'Е': 'И', 'Ё': 'И', 'Э': 'И', 'Й': 'И',
+		'Ю': 'У',
+	}
+)
+
+// Звонкие согласные и их глухие пары; оглушаются на конце слова и перед глухими
+var russianDevoiced = map[rune]rune{
+	'Б': 'П', 'З': 'С', 'Д': 'Т', 'В': 'Ф', 'Г': 'К', 'Ж': 'Ш',
+}
+
+const russianVoiceless = "ПСТФКШХЦЧЩ"
+
+// Russian возвращает ключ упрощенного русского Metaphone: гласные сводятся
+// к А, И, У, звонкие согласные оглушаются, Ъ и Ь отбрасываются, повторы
+// схлопываются. Для строки без кириллицы возвращает пустую строку
+func Russian(s string) string {
+	var words []string
+	for _, word := range strings.FieldsFunc(strings.ToUpper(s), func(r rune) bool {
+		return !unicode.Is(unicode.Cyrillic, r)
+	}) {
+		if key := russianWord(word); key != "" {
+			words = append(words, key)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+func russianWord(word string) string {
+	word = strings.NewReplacer("ТС", "Ц", "ДС", "Ц").Replace(russianVowelPairs.Replace(word))
+
+	var letters []rune
+	for _, r := range word {
+		if r == 'Ъ' || r == 'Ь' {
+			continue
+		}
+		if vowel, ok := russianVowels[r]; ok {
+			r = vowel
+		}
+		letters = append(letters, r)
+	}
+
+	for i, r := range letters {
+		voiceless, ok := russianDevoiced[r]
+		if ok && (i == len(letters)-1 || strings.ContainsRune(russianVoiceless, letters[i+1])) {
+			letters[i] = voiceless
+		}
+	}
+
+	var b strings.Builder
+	var prev rune
+	for _, r := range letters {
+		if r != prev {
+			b.WriteRune(r)
+		}
+		prev = r
+	}
+	return b.String()
+}
@@ -0,0 +1,967 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"people-service/internal/db"
+	"people-service/internal/dedupe"
+	"people-service/internal/models"
+	"people-service/internal/names"
+
+	sqlite "github.com/glebarez/go-sqlite"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// Коды ошибок нарушения уникальности
+const (
+	pgUniqueViolation      = "23505"
+	sqliteConstraintUnique = 2067
+)
+
+// GormRepository хранит людей в Postgres или SQLite через gorm
+type GormRepository struct {
+	db       *gorm.DB
+	identity models.IdentityRule
+}
+
+func NewGormRepository(db *gorm.DB, identity models.IdentityRule) *GormRepository {
+	return &GormRepository{db: db, identity: identity}
+}
+
+func (r *GormRepository) Create(ctx context.Context, person *models.Person) error {
+	person.IdentityKey = r.identity.Key(person)
+	person.SetNameKeys()
+	person.Version = 1
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(person).Error; err != nil {
+			return err
+		}
+		return recordVersion(tx, models.VersionCreate, nil, person)
+	})
+	if err != nil {
+		return r.translateError(ctx, person, err)
+	}
+	return nil
+}
+
+func (r *GormRepository) Get(ctx context.Context, id uint) (*models.Person, error) {
+	var person models.Person
+	if err := r.db.WithContext(ctx).First(&person, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &person, nil
+}
+
+func (r *GormRepository) GetByExternalID(ctx context.Context, externalID string) (*models.Person, error) {
+	var person models.Person
+	if err := r.db.WithContext(ctx).Where("external_id = ?", externalID).First(&person).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &person, nil
+}
+
+func (r *GormRepository) List(ctx context.Context, filter models.PersonFilter) (*Page, error) {
+	q, err := parseListQuery(filter)
+	if err != nil {
+		return nil, err
+	}
+	query := r.applyFilter(r.db.WithContext(ctx).Model(&models.Person{}), filter)
+
+	// Получаем общее количество записей (для пагинации)
+	total := int64(-1)
+	if !filter.SkipCount {
+		if err := query.Count(&total).Error; err != nil {
+			return nil, fmt.Errorf("ошибка получения общего количества: %w", err)
+		}
+	}
+
+	if q.cursor != nil {
+		condition, args := keysetCondition(q.sort, q.cursor.Key, q.cursor.Prev)
+		query = query.Where(condition, args...)
+	}
+	for _, field := range q.sort {
+		if q.reversed() {
+			field.Desc = !field.Desc
+		}
+		query = query.Order(orderClause(field))
+	}
+
+	// Получаем данные; лишняя запись показывает, есть ли следующая страница
+	var people []models.Person
+	if err := query.Offset(q.offset).
+		Limit(q.limit + 1).
+		Find(&people).Error; err != nil {
+		return nil, fmt.Errorf("ошибка получения списка: %w", err)
+	}
+
+	return q.page(people, total), nil
+}
+
+func (r *GormRepository) Update(ctx context.Context, person *models.Person) error {
+	return r.update(ctx, person, models.VersionUpdate)
+}
+
+// update сохраняет person и пишет в историю версию с операцией op
+func (r *GormRepository) update(ctx context.Context, person *models.Person, op models.VersionOperation) error {
+	person.SetNameKeys()
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var before models.Person
+		if err := tx.First(&before, person.ID).Error; err != nil {
+			return notFound(err)
+		}
+		person.IdentityKey = r.identity.KeyOnUpdate(&before, person)
+		if err := saveVersioned(tx, person); err != nil {
+			return err
+		}
+		return recordVersion(tx, op, &before, person)
+	})
+	if err != nil {
+		return r.translateError(ctx, person, err)
+	}
+	return nil
+}
+
+func (r *GormRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		_, err := deletePerson(tx, id, models.VersionDelete, 0)
+		return err
+	})
+}
+
+// deletePerson помечает человека удаленным, пишет версию с операцией op и
+// возвращает запись до удаления. Если expected не 0, запись удаляется, только
+// пока ее версия равна expected, как в saveVersioned
+func deletePerson(tx *gorm.DB, id uint, op models.VersionOperation, expected int) (*models.Person, error) {
+	var person models.Person
+	if err := tx.First(&person, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+
+	query := tx.Model(&person)
+	if expected != 0 {
+		query = query.Where("version = ?", expected)
+	}
+	before := person
+	person.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	person.Version++
+	// Удаление тоже новая версия записи, как и в истории
+	result := query.UpdateColumns(map[string]any{"deleted_at": person.DeletedAt, "version": person.Version})
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = ErrVersionConflict
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &before, recordVersion(tx, op, &before, &person)
+}
+
+// sortColumn возвращает выражение для поля из models.SortableFields;
+// пустое отчество сортируется как пустая строка, как и в MemoryRepository
+func sortColumn(field string) string {
+	if field == "patronymic" {
+		return "COALESCE(patronymic, '')"
+	}
+	return field
+}
+
+func orderClause(field models.SortField) string {
+	if field.Desc {
+		return sortColumn(field.Field) + " DESC"
+	}
+	return sortColumn(field.Field) + " ASC"
+}
+
+// keysetCondition отбирает записи строго после key в порядке sort
+// (или строго перед ним, если prev):
+// (a > ?) OR (a = ? AND b > ?) OR ...
+func keysetCondition(sort []models.SortField, key models.SortKey, prev bool) (string, []any) {
+	var clauses []string
+	var args []any
+	for i, field := range sort {
+		var parts []string
+		for _, equal := range sort[:i] {
+			parts = append(parts, sortColumn(equal.Field)+" = ?")
+			args = append(args, key.Value(equal.Field))
+		}
+		op := " > ?"
+		if field.Desc != prev {
+			op = " < ?"
+		}
+		parts = append(parts, sortColumn(field.Field)+op)
+		args = append(args, key.Value(field.Field))
+		clauses = append(clauses, "("+strings.Join(parts, " AND ")+")")
+	}
+	return "(" + strings.Join(clauses, " OR ") + ")", args
+}
+
+// applyFilter добавляет к запросу условия фильтра
+func (r *GormRepository) applyFilter(query *gorm.DB, filter models.PersonFilter) *gorm.DB {
+	filter.Normalize()
+
+	for _, field := range [][2]string{
+		{"name", filter.Name},
+		{"surname", filter.Surname},
+		{"patronymic", filter.Patronymic},
+	} {
+		if field[1] == "" {
+			continue
+		}
+		switch mode := filter.MatchMode(); mode {
+		case models.MatchTranslit, models.MatchPhonetic:
+			query = r.matchKeys(query, field[0], models.NewNameKeys(field[1]), mode)
+		default:
+			query = r.matchFold(query, field[0], field[1], mode)
+		}
+	}
+
+	if filter.Age != nil {
+		query = query.Where("age = ?", *filter.Age)
+	}
+	if filter.AgeMin != nil {
+		query = query.Where("age >= ?", *filter.AgeMin)
+	}
+	if filter.AgeMax != nil {
+		query = query.Where("age <= ?", *filter.AgeMax)
+	}
+	if len(filter.Gender) > 0 {
+		query = query.Where("gender IN ?", filter.Gender)
+	}
+	if len(filter.Nationality) > 0 {
+		query = query.Where("nationality IN ?", filter.Nationality)
+	}
+
+	if !filter.CreatedFrom.IsZero() {
+		query = query.Where("created_at >= ?", filter.CreatedFrom)
+	}
+	if !filter.CreatedTo.IsZero() {
+		query = query.Where("created_at < ?", filter.CreatedTo)
+	}
+	if !filter.UpdatedFrom.IsZero() {
+		query = query.Where("updated_at >= ?", filter.UpdatedFrom)
+	}
+	if !filter.UpdatedTo.IsZero() {
+		query = query.Where("updated_at < ?", filter.UpdatedTo)
+	}
+
+	for _, field := range filter.IsNull {
+		switch field {
+		case "patronymic", "gender", "nationality":
+			query = query.Where("(" + field + " IS NULL OR " + field + " = '')")
+		}
+	}
+
+	return query
+}
+
+// matchFold добавляет регистронезависимое сравнение column с value в режиме
+// mode: ILIKE в Postgres, unicode_lower в SQLite (см. db.init)
+func (r *GormRepository) matchFold(query *gorm.DB, column, value, mode string) *gorm.DB {
+	pattern := value
+	switch mode {
+	case models.MatchContains:
+		pattern = "%" + value + "%"
+	case models.MatchPrefix:
+		pattern = value + "%"
+	}
+
+	if r.db.Dialector.Name() == db.DriverSQLite {
+		if mode == models.MatchExact {
+			return query.Where("unicode_lower("+column+") = ?", strings.ToLower(value))
+		}
+		return query.Where("unicode_lower("+column+") LIKE ?", strings.ToLower(pattern))
+	}
+	if mode == models.MatchExact {
+		return query.Where("lower("+column+") = lower(?)", value)
+	}
+	return query.Where(column+" ILIKE ?", pattern)
+}
+
+// matchKeys добавляет сравнение ключей поиска части имени column
+// (колонки column_translit, column_metaphone, column_phonetic) с ключами value
+func (r *GormRepository) matchKeys(query *gorm.DB, column string, value models.NameKeys, mode string) *gorm.DB {
+	var conditions []string
+	var args []any
+	add := func(suffix, key string) {
+		if key != "" {
+			conditions = append(conditions, column+"_"+suffix+" = ?")
+			args = append(args, key)
+		}
+	}
+
+	add("translit", value.Translit)
+	if mode == models.MatchPhonetic {
+		add("metaphone", value.Metaphone)
+		add("phonetic", value.Phonetic)
+	}
+	if len(conditions) == 0 {
+		return query.Where("1 = 0")
+	}
+	return query.Where("("+strings.Join(conditions, " OR ")+")", args...)
+}
+
+// BackfillNameKeys вычисляет ключи поиска для записей, сохраненных до их
+// появления (миграция 0007), включая удаленные, и возвращает число записей
+func (r *GormRepository) BackfillNameKeys(ctx context.Context) (int64, error) {
+	var people []models.Person
+	var updated int64
+	err := r.db.WithContext(ctx).Unscoped().Where("name_translit IS NULL").
+		FindInBatches(&people, 500, func(_ *gorm.DB, _ int) error {
+			for i := range people {
+				people[i].SetNameKeys()
+				columns := make(map[string]any)
+				for prefix, keys := range map[string]models.NameKeys{
+					"name":       people[i].NameKeys,
+					"surname":    people[i].SurnameKeys,
+					"patronymic": people[i].PatronymicKeys,
+				} {
+					columns[prefix+"_translit"] = keys.Translit
+					columns[prefix+"_metaphone"] = keys.Metaphone
+					columns[prefix+"_phonetic"] = keys.Phonetic
+				}
+				// UpdateColumns не меняет updated_at и версию: содержимое записи то же
+				err := r.db.WithContext(ctx).Unscoped().Model(&people[i]).UpdateColumns(columns).Error
+				if err != nil {
+					return err
+				}
+				updated++
+			}
+			return nil
+		}).Error
+	return updated, err
+}
+
+// BackfillIdentityKeys приводит identity_key неудаленных записей к правилу
+// из конфигурации и возвращает число измененных записей. При правиле без
+// ключа ключи стираются; иначе запись без ключа получает его, если ключ не
+// занят: из нескольких копий ключ достается самой ранней, остальные
+// остаются с NULL
+func (r *GormRepository) BackfillIdentityKeys(ctx context.Context) (int64, error) {
+	if r.identity.Key(&models.Person{}) == nil {
+		result := r.db.WithContext(ctx).Model(&models.Person{}).
+			Where("identity_key IS NOT NULL").
+			UpdateColumn("identity_key", nil)
+		return result.RowsAffected, result.Error
+	}
+
+	var people []models.Person
+	var updated int64
+	err := r.db.WithContext(ctx).Where("identity_key IS NULL").Order("id").
+		FindInBatches(&people, 500, func(_ *gorm.DB, _ int) error {
+			for i := range people {
+				key := r.identity.Key(&people[i])
+				taken := r.db.WithContext(ctx).Model(&models.Person{}).
+					Select("1").
+					Where("identity_key = ?", *key)
+				// UpdateColumn не меняет updated_at и версию: содержимое записи то же
+				result := r.db.WithContext(ctx).Model(&people[i]).
+					Where("NOT EXISTS (?)", taken).
+					UpdateColumn("identity_key", *key)
+				if result.Error != nil {
+					return result.Error
+				}
+				updated += result.RowsAffected
+			}
+			return nil
+		}).Error
+	return updated, err
+}
+
+func (r *GormRepository) All(ctx context.Context) ([]models.Person, error) {
+	var people []models.Person
+	if err := r.db.WithContext(ctx).Order("id").Find(&people).Error; err != nil {
+		return nil, err
+	}
+	return people, nil
+}
+
+func (r *GormRepository) Stats(ctx context.Context, filter models.PersonFilter, bucketWidth int) (*models.PeopleStats, error) {
+	filtered := func() *gorm.DB {
+		return r.applyFilter(r.db.WithContext(ctx).Model(&models.Person{}), filter)
+	}
+	// knownAge оставляет записи с определенным возрастом
+	knownAge := func() *gorm.DB {
+		return filtered().Where("age > 0")
+	}
+
+	var summary struct {
+		Total   int64
+		Unknown int64
+		Average *float64
+		Min     *int
+		Max     *int
+	}
+	err := filtered().
+		Select("count(*) AS total, coalesce(sum(CASE WHEN age > 0 THEN 0 ELSE 1 END), 0) AS unknown, " +
+			"CAST(avg(NULLIF(age, 0)) AS double precision) AS average, min(NULLIF(age, 0)) AS min, max(NULLIF(age, 0)) AS max").
+		Scan(&summary).Error
+	if err != nil {
+		return nil, fmt.Errorf("ошибка подсчета записей: %w", err)
+	}
+	stats := &models.PeopleStats{
+		Total:       summary.Total,
+		Gender:      []models.CountBucket{},
+		Nationality: []models.CountBucket{},
+		BucketWidth: bucketWidth,
+		Age:         models.AgeStats{Unknown: summary.Unknown, Average: summary.Average, Min: summary.Min, Max: summary.Max},
+	}
+
+	for _, group := range []struct {
+		column string
+		target *[]models.CountBucket
+	}{
+		{"gender", &stats.Gender},
+		{"nationality", &stats.Nationality},
+	} {
+		value := "coalesce(" + group.column + ", '')"
+		err := filtered().
+			Select(value + " AS value, count(*) AS count").
+			Group(value).
+			Order("count(*) DESC").
+			Order(value).
+			Scan(group.target).Error
+		if err != nil {
+			return nil, fmt.Errorf("ошибка группировки по %s: %w", group.column, err)
+		}
+	}
+
+	var buckets []struct {
+		Bucket int
+		Count  int64
+	}
+	err = knownAge().
+		Select("(age / ?) * ? AS bucket, count(*) AS count", bucketWidth, bucketWidth).
+		Group("bucket").
+		Scan(&buckets).Error
+	if err != nil {
+		return nil, fmt.Errorf("ошибка группировки по возрасту: %w", err)
+	}
+	counts := make(map[int]int64, len(buckets))
+	for _, b := range buckets {
+		counts[b.Bucket] = b.Count
+	}
+	stats.AgeBuckets = fillAgeBuckets(counts, bucketWidth)
+
+	percentiles, err := r.agePercentiles(knownAge)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка расчета процентилей: %w", err)
+	}
+	stats.Age.SetPercentiles(percentiles)
+	return stats, nil
+}
+
+// agePercentiles считает процентили возраста уровней models.Percentiles:
+// percentile_cont в Postgres, в SQLite — по соседним значениям, выбранным
+// с OFFSET из упорядоченного списка. known выбирает записи с известным
+// возрастом; без них возвращает nil
+func (r *GormRepository) agePercentiles(known func() *gorm.DB) ([]float64, error) {
+	if r.db.Dialector.Name() != db.DriverSQLite {
+		columns := make([]string, len(models.Percentiles))
+		for i, level := range models.Percentiles {
+			columns[i] = fmt.Sprintf("percentile_cont(%g) WITHIN GROUP (ORDER BY age)", level)
+		}
+		values := make([]sql.NullFloat64, len(models.Percentiles))
+		targets := make([]any, len(values))
+		for i := range values {
+			targets[i] = &values[i]
+		}
+		if err := known().Select(strings.Join(columns, ", ")).Row().Scan(targets...); err != nil {
+			return nil, err
+		}
+		if !values[0].Valid {
+			return nil, nil
+		}
+		percentiles := make([]float64, len(values))
+		for i, v := range values {
+			percentiles[i] = v.Float64
+		}
+		return percentiles, nil
+	}
+
+	var n int64
+	if err := known().Count(&n).Error; err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, nil
+	}
+	percentiles := make([]float64, len(models.Percentiles))
+	for i, level := range models.Percentiles {
+		lower, fraction := percentilePosition(level, int(n))
+		var neighbors []int
+		err := known().
+			Order("age").Offset(lower).Limit(2).
+			Pluck("age", &neighbors).Error
+		if err != nil {
+			return nil, err
+		}
+		percentiles[i] = interpolate(neighbors, fraction)
+	}
+	return percentiles, nil
+}
+
+// Search в Postgres использует search_vector и триграммный индекс по
+// full_name, в SQLite — таблицу FTS5 people_fts (без основ слов, Config не
+// учитывается) или ранжирование в Go по пачкам записей для режима trigram
+func (r *GormRepository) Search(ctx context.Context, query models.SearchQuery) ([]models.SearchResult, error) {
+	if r.db.Dialector.Name() == db.DriverSQLite {
+		return r.searchSQLite(ctx, query)
+	}
+	if query.Mode == models.SearchTrigram {
+		return r.searchTrigram(ctx, query)
+	}
+
+	results := []models.SearchResult{}
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT people.*,
+		       ts_rank(people.search_vector, q) AS search_rank,
+		       ts_headline(?::regconfig, people.full_name, q, ?) AS search_highlight
+		FROM people, websearch_to_tsquery(?::regconfig, ?) AS q
+		WHERE people.deleted_at IS NULL AND people.search_vector @@ q
+		ORDER BY search_rank DESC, people.id
+		LIMIT ?`,
+		query.Config,
+		fmt.Sprintf("StartSel=%s, StopSel=%s, HighlightAll=true", models.HighlightStart, models.HighlightStop),
+		query.Config, query.Q, query.Limit,
+	).Scan(&results).Error
+	return results, err
+}
+
+// searchTrigram ищет по сходству запроса со словами full_name (оператор <%
+// использует триграммный индекс), подсветка строится в Go
+func (r *GormRepository) searchTrigram(ctx context.Context, query models.SearchQuery) ([]models.SearchResult, error) {
+	results := []models.SearchResult{}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec("SELECT set_config('pg_trgm.word_similarity_threshold', ?, true)",
+			fmt.Sprint(trigramThreshold)).Error
+		if err != nil {
+			return err
+		}
+		return tx.Raw(`
+			SELECT people.*, word_similarity(?, people.full_name) AS search_rank
+			FROM people
+			WHERE people.deleted_at IS NULL AND ? <% people.full_name
+			ORDER BY search_rank DESC, people.id
+			LIMIT ?`,
+			query.Q, query.Q, query.Limit,
+		).Scan(&results).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	terms := searchTerms(query.Q)
+	for i := range results {
+		results[i].Highlight = highlight(fullName(results[i].Person), func(word string) bool {
+			return slices.ContainsFunc(terms, func(term string) bool {
+				return names.Similarity(word, term) >= trigramThreshold
+			})
+		})
+	}
+	return results, nil
+}
+
+func (r *GormRepository) searchSQLite(ctx context.Context, query models.SearchQuery) ([]models.SearchResult, error) {
+	if query.Mode == models.SearchTrigram {
+		// Триграммного индекса в SQLite нет: записи читаются пачками, и между
+		// пачками в памяти остаются только лучшие query.Limit результатов
+		results := []models.SearchResult{}
+		var people []models.Person
+		err := r.db.WithContext(ctx).Order("id").
+			FindInBatches(&people, 500, func(_ *gorm.DB, _ int) error {
+				results = topResults(append(results, searchPeople(people, query)...), query.Limit)
+				return nil
+			}).Error
+		return results, err
+	}
+
+	match := ftsMatchExpression(query.Q)
+	if match == "" {
+		return []models.SearchResult{}, nil
+	}
+	mark := func(column int) string {
+		return fmt.Sprintf("highlight(people_fts, %d, '%s', '%s')", column, models.HighlightStart, models.HighlightStop)
+	}
+
+	// Веса bm25 соответствуют setweight в Postgres: имя и фамилия важнее отчества
+	results := []models.SearchResult{}
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT people.*,
+		       -bm25(people_fts, 2.0, 2.0, 1.0) AS search_rank,
+		       `+mark(0)+` || ' ' || `+mark(1)+` ||
+		       CASE WHEN coalesce(people.patronymic, '') = '' THEN '' ELSE ' ' || `+mark(2)+` END AS search_highlight
+		FROM people_fts
+		JOIN people ON people.id = people_fts.rowid
+		WHERE people_fts MATCH ? AND people.deleted_at IS NULL
+		ORDER BY search_rank DESC, people.id
+		LIMIT ?`,
+		match, query.Limit,
+	).Scan(&results).Error
+	return results, err
+}
+
+func (r *GormRepository) GetMany(ctx context.Context, ids []uint) ([]models.Person, error) {
+	people := []models.Person{}
+	if len(ids) == 0 {
+		return people, nil
+	}
+	err := r.db.WithContext(ctx).Where("id IN ?", ids).Order("id").Find(&people).Error
+	return people, err
+}
+
+// duplicateScore повторяет dedupe.Score на сохраненной транслитерации
+// для пары строк a и b; отчество учитывается, только если оно есть у обоих
+var duplicateScore = fmt.Sprintf(`(
+	%[1]g * similarity(a.name_translit, b.name_translit) +
+	%[2]g * similarity(a.surname_translit, b.surname_translit) +
+	CASE WHEN %[4]s THEN %[3]g * similarity(a.patronymic_translit, b.patronymic_translit) ELSE 0 END
+) / CASE WHEN %[4]s THEN %[5]g ELSE %[6]g END`,
+	dedupe.NameWeight, dedupe.SurnameWeight, dedupe.PatronymicWeight,
+	"coalesce(a.patronymic_translit, '') <> '' AND coalesce(b.patronymic_translit, '') <> ''",
+	dedupe.NameWeight+dedupe.SurnameWeight+dedupe.PatronymicWeight,
+	dedupe.NameWeight+dedupe.SurnameWeight,
+)
+
+// minCandidateSimilarity нижняя граница pg_trgm.similarity_threshold при
+// отборе кандидатов: значение pg_trgm по умолчанию. Ниже индекс почти
+// ничего не отсекает, и поиск превращается в сравнение всех пар
+const minCandidateSimilarity = 0.3
+
+// DuplicatePairs считает оценки в SQL. В Postgres кандидаты отбираются
+// оператором % по триграммному индексу фамилии с порогом, ниже которого пара
+// не наберет query.Threshold; в SQLite — по первой букве фамилии, как
+// dedupe.Pairs
+func (r *GormRepository) DuplicatePairs(ctx context.Context, query models.DuplicatesQuery) ([]dedupe.Pair, bool, error) {
+	pairs := []dedupe.Pair{}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		candidates := "substr(a.surname_translit, 1, 1) = substr(b.surname_translit, 1, 1)"
+		if r.db.Dialector.Name() != db.DriverSQLite {
+			threshold := max(dedupe.MinSurnameSimilarity(query.Threshold), minCandidateSimilarity)
+			err := tx.Exec("SELECT set_config('pg_trgm.similarity_threshold', ?, true)",
+				fmt.Sprint(threshold)).Error
+			if err != nil {
+				return err
+			}
+			candidates = "a.surname_translit % b.surname_translit"
+		}
+
+		return tx.Raw(`
+			SELECT a, b, score
+			FROM (
+				SELECT a.id AS a, b.id AS b, `+duplicateScore+` AS score
+				FROM people a
+				JOIN people b ON b.id > a.id AND `+candidates+`
+				WHERE a.deleted_at IS NULL AND b.deleted_at IS NULL
+			) pairs
+			WHERE score >= ?
+			ORDER BY score DESC, a, b
+			LIMIT ? OFFSET ?`,
+			query.Threshold, query.Limit+1, query.Offset(),
+		).Scan(&pairs).Error
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	more := len(pairs) > query.Limit
+	if more {
+		pairs = pairs[:query.Limit]
+	}
+	return pairs, more, nil
+}
+
+func (r *GormRepository) Merge(ctx context.Context, survivor *models.Person, merged []models.Person, fields models.RawJSON) ([]models.PersonMerge, error) {
+	survivor.SetNameKeys()
+	now := time.Now()
+
+	var merges []models.PersonMerge
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var before models.Person
+		if err := tx.First(&before, survivor.ID).Error; err != nil {
+			return notFound(err)
+		}
+		survivor.IdentityKey = r.identity.KeyOnUpdate(&before, survivor)
+
+		// Сначала удаляем дубликаты, чтобы survivor мог забрать их имя
+		merges = make([]models.PersonMerge, 0, len(merged))
+		for _, person := range merged {
+			deleted, err := deletePerson(tx, person.ID, models.VersionMerge, person.Version)
+			if err != nil {
+				return err
+			}
+			merge, err := newMerge(survivor.ID, deleted, fields, now)
+			if err != nil {
+				return err
+			}
+			merges = append(merges, merge)
+		}
+
+		if err := saveVersioned(tx, survivor); err != nil {
+			return err
+		}
+		if err := recordVersion(tx, models.VersionMerge, &before, survivor); err != nil {
+			return err
+		}
+		return tx.Create(&merges).Error
+	})
+	if err != nil {
+		// Транзакция уже откатана, конфликт можно искать вне ее
+		return nil, r.translateError(ctx, survivor, err)
+	}
+	return merges, nil
+}
+
+func (r *GormRepository) Merges(ctx context.Context, survivorID uint) ([]models.PersonMerge, error) {
+	var merges []models.PersonMerge
+	err := r.db.WithContext(ctx).
+		Where("survivor_id = ?", survivorID).
+		Order("id").
+		Find(&merges).Error
+	return merges, err
+}
+
+func (r *GormRepository) ListDeleted(ctx context.Context, filter models.PersonFilter) ([]models.Person, int64, error) {
+	var people []models.Person
+	query := r.db.WithContext(ctx).Unscoped().Model(&models.Person{}).Where("deleted_at IS NOT NULL")
+	query = r.applyFilter(query, filter)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("ошибка получения общего количества: %w", err)
+	}
+
+	offset, limit := pagination(filter)
+	if err := query.Order("deleted_at DESC").Order("id DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&people).Error; err != nil {
+		return nil, 0, fmt.Errorf("ошибка получения списка: %w", err)
+	}
+
+	return people, total, nil
+}
+
+func (r *GormRepository) Restore(ctx context.Context, id uint) (*models.Person, error) {
+	var person models.Person
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().
+			Where("deleted_at IS NOT NULL").
+			First(&person, id).Error
+		if err != nil {
+			return notFound(err)
+		}
+
+		before := person
+		person.DeletedAt = gorm.DeletedAt{}
+		person.Version++
+		err = tx.Unscoped().
+			Model(&person).
+			Updates(map[string]any{"deleted_at": nil, "version": person.Version}).Error
+		if err != nil {
+			return err
+		}
+		return recordVersion(tx, models.VersionRestore, &before, &person)
+	})
+	if err != nil {
+		return nil, r.translateError(ctx, &person, err)
+	}
+	return &person, nil
+}
+
+func (r *GormRepository) Purge(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Записи о слиянии id в другого человека остаются в его журнале
+		err := tx.Where("survivor_id = ?", id).Delete(&models.PersonMerge{}).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("person_id = ?", id).Delete(&models.PersonVersion{}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Unscoped().Delete(&models.Person{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+func (r *GormRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		expired := tx.Unscoped().Model(&models.Person{}).
+			Select("id").
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", before)
+
+		// Слитые записи тоже удалены, но журнал живого survivor сохраняется
+		err := tx.Where("survivor_id IN (?)", expired).Delete(&models.PersonMerge{}).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("person_id IN (?)", expired).Delete(&models.PersonVersion{}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Unscoped().
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+			Delete(&models.Person{})
+		purged = result.RowsAffected
+		return result.Error
+	})
+	return purged, err
+}
+
+func (r *GormRepository) History(ctx context.Context, personID uint) ([]models.PersonVersion, error) {
+	var versions []models.PersonVersion
+	err := r.db.WithContext(ctx).
+		Where("person_id = ?", personID).
+		Order("version").
+		Find(&versions).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, ErrNotFound
+	}
+	return versions, nil
+}
+
+func (r *GormRepository) HistoryMany(ctx context.Context, personIDs []uint) (map[uint][]models.PersonVersion, error) {
+	history := make(map[uint][]models.PersonVersion)
+	if len(personIDs) == 0 {
+		return history, nil
+	}
+
+	var versions []models.PersonVersion
+	err := r.db.WithContext(ctx).
+		Where("person_id IN ?", personIDs).
+		Order("person_id, version").
+		Find(&versions).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range versions {
+		history[v.PersonID] = append(history[v.PersonID], v)
+	}
+	return history, nil
+}
+
+func (r *GormRepository) Version(ctx context.Context, personID uint, version int) (*models.PersonVersion, error) {
+	var v models.PersonVersion
+	err := r.db.WithContext(ctx).
+		Where("person_id = ? AND version = ?", personID, version).
+		First(&v).Error
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &v, nil
+}
+
+func (r *GormRepository) Revert(ctx context.Context, personID uint, version int) (*models.Person, error) {
+	v, err := r.Version(ctx, personID, version)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := v.Person()
+	if err != nil {
+		return nil, err
+	}
+	person, err := r.Get(ctx, personID)
+	if err != nil {
+		return nil, err
+	}
+
+	person.SetFields(snapshot.Fields())
+	if err := r.update(ctx, person, models.VersionRevert); err != nil {
+		return nil, err
+	}
+	return person, nil
+}
+
+// saveVersioned сохраняет person, только если его версия в базе не менялась
+// с момента чтения, и увеличивает версию; иначе возвращает ErrVersionConflict
+func saveVersioned(tx *gorm.DB, person *models.Person) error {
+	expected := person.Version
+	person.Version++
+
+	// Явный Select не дает Save превратить неудачный UPDATE в INSERT
+	result := tx.Select("*").Where("version = ?", expected).Save(person)
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = ErrVersionConflict
+	}
+	if result.Error != nil {
+		person.Version = expected
+		return result.Error
+	}
+	return nil
+}
+
+// recordVersion пишет в историю версию after
+func recordVersion(tx *gorm.DB, op models.VersionOperation, before, after *models.Person) error {
+	version, err := newVersion(tx.Statement.Context, op, before, after)
+	if err != nil {
+		return err
+	}
+	return tx.Create(&version).Error
+}
+
+// notFound превращает gorm.ErrRecordNotFound в ErrNotFound
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// translateError превращает нарушение уникального индекса в *DuplicateError
+// с ID уже существующей записи
+func (r *GormRepository) translateError(ctx context.Context, person *models.Person, err error) error {
+	if !isUniqueViolation(err) {
+		return err
+	}
+
+	var existing models.Person
+	if person.ExternalID != nil {
+		lookup := r.db.WithContext(ctx).
+			Where("external_id = ?", *person.ExternalID).
+			Where("id <> ?", person.ID).
+			First(&existing)
+		if lookup.Error == nil {
+			return &DuplicateError{ExistingID: existing.ID, Field: DuplicateExternalID}
+		}
+	}
+
+	dup := &DuplicateError{Field: DuplicateIdentity}
+	if person.IdentityKey != nil {
+		lookup := r.db.WithContext(ctx).
+			Where("identity_key = ?", *person.IdentityKey).
+			Where("id <> ?", person.ID).
+			First(&existing)
+		if lookup.Error == nil {
+			dup.ExistingID = existing.ID
+		}
+	}
+	return dup
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == pgUniqueViolation
+	}
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code() == sqliteConstraintUnique
+	}
+	return false
+}
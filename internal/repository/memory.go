@@ -0,0 +1,713 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"people-service/internal/dedupe"
+	"people-service/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// MemoryRepository хранит людей в памяти процесса; предназначен для
+// тестов и повторяет семантику GormRepository, включая мягкое удаление
+type MemoryRepository struct {
+	mu        sync.RWMutex
+	people    map[uint]models.Person
+	merges    []models.PersonMerge
+	nextID    uint
+	mergeID   uint
+	versions  []models.PersonVersion
+	versionID uint
+	identity  models.IdentityRule
+}
+
+func NewMemoryRepository(identity models.IdentityRule) *MemoryRepository {
+	return &MemoryRepository{people: make(map[uint]models.Person), nextID: 1, identity: identity}
+}
+
+func (r *MemoryRepository) Create(ctx context.Context, person *models.Person) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	person.IdentityKey = r.identity.Key(person)
+	person.SetNameKeys()
+	if err := r.checkUnique(person); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	person.ID = r.nextID
+	person.Version = 1
+	person.CreatedAt = now
+	person.UpdatedAt = now
+	r.nextID++
+
+	r.people[person.ID] = clonePerson(*person)
+	return r.recordVersion(ctx, models.VersionCreate, nil, person)
+}
+
+func (r *MemoryRepository) Get(ctx context.Context, id uint) (*models.Person, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	person, ok := r.people[id]
+	if !ok || person.DeletedAt.Valid {
+		return nil, ErrNotFound
+	}
+	person = clonePerson(person)
+	return &person, nil
+}
+
+func (r *MemoryRepository) GetByExternalID(ctx context.Context, externalID string) (*models.Person, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, person := range r.people {
+		if !person.DeletedAt.Valid && person.ExternalID != nil && *person.ExternalID == externalID {
+			person = clonePerson(person)
+			return &person, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *MemoryRepository) List(ctx context.Context, filter models.PersonFilter) (*Page, error) {
+	q, err := parseListQuery(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matched []models.Person
+	for _, person := range r.people {
+		if !person.DeletedAt.Valid && matchFilter(person, filter) {
+			matched = append(matched, clonePerson(person))
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return compareBySort(matched[i], matched[j], q.sort) < 0
+	})
+
+	total := int64(len(matched))
+	if filter.SkipCount {
+		total = -1
+	}
+
+	if q.cursor != nil {
+		pivot := q.cursor.Key.Person()
+		var window []models.Person
+		for _, person := range matched {
+			c := compareBySort(person, pivot, q.sort)
+			if (c > 0 && !q.cursor.Prev) || (c < 0 && q.cursor.Prev) {
+				window = append(window, person)
+			}
+		}
+		if q.cursor.Prev {
+			slices.Reverse(window)
+		}
+		matched = window
+	}
+
+	if q.offset >= len(matched) {
+		return q.page([]models.Person{}, total), nil
+	}
+	return q.page(matched[q.offset:min(q.offset+q.limit+1, len(matched))], total), nil
+}
+
+func (r *MemoryRepository) Update(ctx context.Context, person *models.Person) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.update(ctx, person, models.VersionUpdate)
+}
+
+// update сохраняет person и пишет версию с операцией op; вызывается под r.mu
+func (r *MemoryRepository) update(ctx context.Context, person *models.Person, op models.VersionOperation) error {
+	existing, ok := r.people[person.ID]
+	if !ok || existing.DeletedAt.Valid {
+		return ErrNotFound
+	}
+	if existing.Version != person.Version {
+		return ErrVersionConflict
+	}
+	person.IdentityKey = r.identity.KeyOnUpdate(&existing, person)
+	person.SetNameKeys()
+	if err := r.checkUnique(person); err != nil {
+		return err
+	}
+	person.Version++
+	if person.CreatedAt.IsZero() {
+		person.CreatedAt = existing.CreatedAt
+	}
+	person.UpdatedAt = time.Now()
+	r.people[person.ID] = clonePerson(*person)
+	return r.recordVersion(ctx, op, &existing, person)
+}
+
+func (r *MemoryRepository) Delete(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.delete(ctx, id, models.VersionDelete)
+}
+
+// delete помечает человека удаленным и пишет версию с операцией op;
+// вызывается под r.mu
+func (r *MemoryRepository) delete(ctx context.Context, id uint, op models.VersionOperation) error {
+	person, ok := r.people[id]
+	if !ok || person.DeletedAt.Valid {
+		return ErrNotFound
+	}
+	before := person
+	person.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	person.Version++
+	r.people[id] = person
+	return r.recordVersion(ctx, op, &before, &person)
+}
+
+func (r *MemoryRepository) All(ctx context.Context) ([]models.Person, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var people []models.Person
+	for _, person := range r.people {
+		if !person.DeletedAt.Valid {
+			people = append(people, clonePerson(person))
+		}
+	}
+	sort.Slice(people, func(i, j int) bool { return people[i].ID < people[j].ID })
+	return people, nil
+}
+
+func (r *MemoryRepository) Stats(ctx context.Context, filter models.PersonFilter, bucketWidth int) (*models.PeopleStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	gender := make(map[string]int64)
+	nationality := make(map[string]int64)
+	buckets := make(map[int]int64)
+	var total, unknown int64
+	var ages []int
+	for _, person := range r.people {
+		if person.DeletedAt.Valid || !matchFilter(person, filter) {
+			continue
+		}
+		total++
+		gender[person.Gender]++
+		nationality[person.Nationality]++
+		if person.Age <= 0 {
+			unknown++
+			continue
+		}
+		buckets[person.Age/bucketWidth*bucketWidth]++
+		ages = append(ages, person.Age)
+	}
+
+	stats := &models.PeopleStats{
+		Total:       total,
+		Gender:      countBuckets(gender),
+		Nationality: countBuckets(nationality),
+		BucketWidth: bucketWidth,
+		AgeBuckets:  fillAgeBuckets(buckets, bucketWidth),
+		Age:         models.AgeStats{Unknown: unknown},
+	}
+	if len(ages) == 0 {
+		return stats, nil
+	}
+
+	slices.Sort(ages)
+	sum := 0
+	for _, age := range ages {
+		sum += age
+	}
+	average := float64(sum) / float64(len(ages))
+	stats.Age.Average, stats.Age.Min, stats.Age.Max = &average, &ages[0], &ages[len(ages)-1]
+
+	percentiles := make([]float64, len(models.Percentiles))
+	for i, level := range models.Percentiles {
+		lower, fraction := percentilePosition(level, len(ages))
+		percentiles[i] = interpolate(ages[lower:min(lower+2, len(ages))], fraction)
+	}
+	stats.Age.SetPercentiles(percentiles)
+	return stats, nil
+}
+
+// Search ранжирует людей в Go; Config не учитывается, так как основы слов
+// в памяти не вычисляются
+func (r *MemoryRepository) Search(ctx context.Context, query models.SearchQuery) ([]models.SearchResult, error) {
+	people, err := r.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return searchPeople(people, query), nil
+}
+
+func (r *MemoryRepository) GetMany(ctx context.Context, ids []uint) ([]models.Person, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	people := []models.Person{}
+	for _, id := range ids {
+		if p, ok := r.people[id]; ok && !p.DeletedAt.Valid {
+			people = append(people, clonePerson(p))
+		}
+	}
+	slices.SortFunc(people, func(a, b models.Person) int { return cmp.Compare(a.ID, b.ID) })
+	return slices.CompactFunc(people, func(a, b models.Person) bool { return a.ID == b.ID }), nil
+}
+
+func (r *MemoryRepository) DuplicatePairs(ctx context.Context, query models.DuplicatesQuery) ([]dedupe.Pair, bool, error) {
+	people, err := r.All(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	pairs := dedupe.Pairs(people, query.Threshold)
+
+	start := min(query.Offset(), len(pairs))
+	end := min(start+query.Limit, len(pairs))
+	return pairs[start:end], end < len(pairs), nil
+}
+
+func (r *MemoryRepository) Merge(ctx context.Context, survivor *models.Person, merged []models.Person, fields models.RawJSON) ([]models.PersonMerge, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.people[survivor.ID]
+	if !ok || existing.DeletedAt.Valid {
+		return nil, ErrNotFound
+	}
+	if existing.Version != survivor.Version {
+		return nil, ErrVersionConflict
+	}
+	for _, m := range merged {
+		stored, ok := r.people[m.ID]
+		if !ok || stored.DeletedAt.Valid {
+			return nil, ErrNotFound
+		}
+		if stored.Version != m.Version {
+			return nil, ErrVersionConflict
+		}
+	}
+
+	// Проверяем уникальность так, будто дубликаты уже удалены
+	now := time.Now()
+	deleted := make(map[uint]models.Person, len(merged))
+	for _, m := range merged {
+		deleted[m.ID] = r.people[m.ID]
+		person := r.people[m.ID]
+		person.DeletedAt = gorm.DeletedAt{Time: now, Valid: true}
+		r.people[m.ID] = person
+	}
+	survivor.IdentityKey = r.identity.KeyOnUpdate(&existing, survivor)
+	survivor.SetNameKeys()
+	if err := r.checkUnique(survivor); err != nil {
+		for id, person := range deleted {
+			r.people[id] = person
+		}
+		return nil, err
+	}
+
+	merges := make([]models.PersonMerge, 0, len(merged))
+	for _, m := range merged {
+		before, after := deleted[m.ID], r.people[m.ID]
+		if err := r.recordVersion(ctx, models.VersionMerge, &before, &after); err != nil {
+			return nil, err
+		}
+		merge, err := newMerge(survivor.ID, &before, fields, now)
+		if err != nil {
+			return nil, err
+		}
+		merges = append(merges, merge)
+	}
+	survivor.Version++
+	survivor.UpdatedAt = now
+	r.people[survivor.ID] = clonePerson(*survivor)
+	if err := r.recordVersion(ctx, models.VersionMerge, &existing, survivor); err != nil {
+		return nil, err
+	}
+	for i := range merges {
+		r.mergeID++
+		merges[i].ID = r.mergeID
+		r.merges = append(r.merges, merges[i])
+	}
+	return merges, nil
+}
+
+func (r *MemoryRepository) Merges(ctx context.Context, survivorID uint) ([]models.PersonMerge, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var merges []models.PersonMerge
+	for _, m := range r.merges {
+		if m.SurvivorID == survivorID {
+			merges = append(merges, m)
+		}
+	}
+	return merges, nil
+}
+
+func (r *MemoryRepository) ListDeleted(ctx context.Context, filter models.PersonFilter) ([]models.Person, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matched []models.Person
+	for _, person := range r.people {
+		if person.DeletedAt.Valid && matchFilter(person, filter) {
+			matched = append(matched, clonePerson(person))
+		}
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		if matched[i].DeletedAt.Time.Equal(matched[j].DeletedAt.Time) {
+			return matched[i].ID > matched[j].ID
+		}
+		return matched[i].DeletedAt.Time.After(matched[j].DeletedAt.Time)
+	})
+
+	total := int64(len(matched))
+	offset, limit := pagination(filter)
+	if offset >= len(matched) {
+		return []models.Person{}, total, nil
+	}
+	return matched[offset:min(offset+limit, len(matched))], total, nil
+}
+
+func (r *MemoryRepository) Restore(ctx context.Context, id uint) (*models.Person, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	person, ok := r.people[id]
+	if !ok || !person.DeletedAt.Valid {
+		return nil, ErrNotFound
+	}
+	if err := r.checkUnique(&person); err != nil {
+		return nil, err
+	}
+	before := person
+	person.DeletedAt = gorm.DeletedAt{}
+	person.Version++
+	r.people[id] = person
+	if err := r.recordVersion(ctx, models.VersionRestore, &before, &person); err != nil {
+		return nil, err
+	}
+
+	person = clonePerson(person)
+	return &person, nil
+}
+
+func (r *MemoryRepository) Purge(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.people[id]; !ok {
+		return ErrNotFound
+	}
+	r.purge(id)
+	return nil
+}
+
+func (r *MemoryRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var purged int64
+	for id, person := range r.people {
+		if person.DeletedAt.Valid && person.DeletedAt.Time.Before(before) {
+			r.purge(id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// purge удаляет человека и журнал слияний в него; записи о слиянии id
+// в другого человека остаются
+func (r *MemoryRepository) purge(id uint) {
+	delete(r.people, id)
+	merges := r.merges[:0]
+	for _, m := range r.merges {
+		if m.SurvivorID != id {
+			merges = append(merges, m)
+		}
+	}
+	r.merges = merges
+
+	versions := r.versions[:0]
+	for _, v := range r.versions {
+		if v.PersonID != id {
+			versions = append(versions, v)
+		}
+	}
+	r.versions = versions
+}
+
+func (r *MemoryRepository) History(ctx context.Context, personID uint) ([]models.PersonVersion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var versions []models.PersonVersion
+	for _, v := range r.versions {
+		if v.PersonID == personID {
+			versions = append(versions, v)
+		}
+	}
+	if len(versions) == 0 {
+		return nil, ErrNotFound
+	}
+	return versions, nil
+}
+
+func (r *MemoryRepository) HistoryMany(ctx context.Context, personIDs []uint) (map[uint][]models.PersonVersion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	wanted := make(map[uint]bool, len(personIDs))
+	for _, id := range personIDs {
+		wanted[id] = true
+	}
+	history := make(map[uint][]models.PersonVersion)
+	for _, v := range r.versions {
+		if wanted[v.PersonID] {
+			history[v.PersonID] = append(history[v.PersonID], v)
+		}
+	}
+	return history, nil
+}
+
+func (r *MemoryRepository) Version(ctx context.Context, personID uint, version int) (*models.PersonVersion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.version(personID, version)
+}
+
+func (r *MemoryRepository) version(personID uint, version int) (*models.PersonVersion, error) {
+	for _, v := range r.versions {
+		if v.PersonID == personID && v.Version == version {
+			return &v, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *MemoryRepository) Revert(ctx context.Context, personID uint, version int) (*models.Person, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	v, err := r.version(personID, version)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := v.Person()
+	if err != nil {
+		return nil, err
+	}
+	person, ok := r.people[personID]
+	if !ok || person.DeletedAt.Valid {
+		return nil, ErrNotFound
+	}
+
+	person = clonePerson(person)
+	person.SetFields(snapshot.Fields())
+	if err := r.update(ctx, &person, models.VersionRevert); err != nil {
+		return nil, err
+	}
+	return &person, nil
+}
+
+// recordVersion добавляет в историю версию after; вызывается под r.mu
+func (r *MemoryRepository) recordVersion(ctx context.Context, op models.VersionOperation, before, after *models.Person) error {
+	version, err := newVersion(ctx, op, before, after)
+	if err != nil {
+		return err
+	}
+	r.versionID++
+	version.ID = r.versionID
+	r.versions = append(r.versions, version)
+	return nil
+}
+
+// checkUnique повторяет частичный уникальный индекс idx_people_identity_key
+func (r *MemoryRepository) checkUnique(person *models.Person) error {
+	for id, other := range r.people {
+		if id != person.ID && !other.DeletedAt.Valid && person.ExternalID != nil &&
+			other.ExternalID != nil && *other.ExternalID == *person.ExternalID {
+			return &DuplicateError{ExistingID: id, Field: DuplicateExternalID}
+		}
+	}
+	if person.IdentityKey == nil {
+		return nil
+	}
+	for id, other := range r.people {
+		if id != person.ID && !other.DeletedAt.Valid &&
+			other.IdentityKey != nil && *other.IdentityKey == *person.IdentityKey {
+			return &DuplicateError{ExistingID: id, Field: DuplicateIdentity}
+		}
+	}
+	return nil
+}
+
+func matchFilter(p models.Person, filter models.PersonFilter) bool {
+	filter.Normalize()
+
+	patronymic := derefString(p.Patronymic)
+	for _, field := range []struct {
+		value  string
+		keys   models.NameKeys
+		filter string
+	}{
+		{p.Name, p.NameKeys, filter.Name},
+		{p.Surname, p.SurnameKeys, filter.Surname},
+		{patronymic, p.PatronymicKeys, filter.Patronymic},
+	} {
+		if field.filter == "" {
+			continue
+		}
+		switch mode := filter.MatchMode(); mode {
+		case models.MatchTranslit, models.MatchPhonetic:
+			if !matchKeys(field.keys, models.NewNameKeys(field.filter), mode) {
+				return false
+			}
+		default:
+			if !matchFold(field.value, field.filter, mode) {
+				return false
+			}
+		}
+	}
+
+	if filter.Age != nil && p.Age != *filter.Age {
+		return false
+	}
+	if filter.AgeMin != nil && p.Age < *filter.AgeMin {
+		return false
+	}
+	if filter.AgeMax != nil && p.Age > *filter.AgeMax {
+		return false
+	}
+	if len(filter.Gender) > 0 && !slices.Contains(filter.Gender, p.Gender) {
+		return false
+	}
+	if len(filter.Nationality) > 0 && !slices.Contains(filter.Nationality, p.Nationality) {
+		return false
+	}
+
+	if !filter.CreatedFrom.IsZero() && p.CreatedAt.Before(filter.CreatedFrom) {
+		return false
+	}
+	if !filter.CreatedTo.IsZero() && !p.CreatedAt.Before(filter.CreatedTo) {
+		return false
+	}
+	if !filter.UpdatedFrom.IsZero() && p.UpdatedAt.Before(filter.UpdatedFrom) {
+		return false
+	}
+	if !filter.UpdatedTo.IsZero() && !p.UpdatedAt.Before(filter.UpdatedTo) {
+		return false
+	}
+
+	for _, field := range filter.IsNull {
+		switch {
+		case field == "patronymic" && patronymic != "",
+			field == "gender" && p.Gender != "",
+			field == "nationality" && p.Nationality != "":
+			return false
+		}
+	}
+	return true
+}
+
+// compareBySort возвращает -1, если a идет раньше b в порядке sort
+func compareBySort(a, b models.Person, sort []models.SortField) int {
+	for _, field := range sort {
+		if c := compareField(a, b, field.Field); c != 0 {
+			if field.Desc {
+				return -c
+			}
+			return c
+		}
+	}
+	return 0
+}
+
+// compareField сравнивает людей по полю из models.SortableFields
+func compareField(a, b models.Person, field string) int {
+	switch field {
+	case "id":
+		return cmp.Compare(a.ID, b.ID)
+	case "name":
+		return cmp.Compare(a.Name, b.Name)
+	case "surname":
+		return cmp.Compare(a.Surname, b.Surname)
+	case "patronymic":
+		return cmp.Compare(derefString(a.Patronymic), derefString(b.Patronymic))
+	case "age":
+		return cmp.Compare(a.Age, b.Age)
+	case "gender":
+		return cmp.Compare(a.Gender, b.Gender)
+	case "nationality":
+		return cmp.Compare(a.Nationality, b.Nationality)
+	case "created_at":
+		return a.CreatedAt.Compare(b.CreatedAt)
+	case "updated_at":
+		return a.UpdatedAt.Compare(b.UpdatedAt)
+	}
+	return 0
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// matchFold сравнивает s с value без учета регистра в режиме mode
+func matchFold(s, value, mode string) bool {
+	s, value = strings.ToLower(s), strings.ToLower(value)
+	switch mode {
+	case models.MatchExact:
+		return s == value
+	case models.MatchPrefix:
+		return strings.HasPrefix(s, value)
+	default:
+		return strings.Contains(s, value)
+	}
+}
+
+// matchKeys сравнивает ключи поиска части имени stored с ключами значения
+// фильтра value, как GormRepository.matchKeys
+func matchKeys(stored, value models.NameKeys, mode string) bool {
+	if value.Translit != "" && stored.Translit == value.Translit {
+		return true
+	}
+	if mode != models.MatchPhonetic {
+		return false
+	}
+	return value.Metaphone != "" && stored.Metaphone == value.Metaphone ||
+		value.Phonetic != "" && stored.Phonetic == value.Phonetic
+}
+
+// clonePerson копирует запись, чтобы вызывающий код не менял хранилище через указатели
+func clonePerson(p models.Person) models.Person {
+	if p.Patronymic != nil {
+		patronymic := *p.Patronymic
+		p.Patronymic = &patronymic
+	}
+	if p.IdentityKey != nil {
+		key := *p.IdentityKey
+		p.IdentityKey = &key
+	}
+	if p.ExternalID != nil {
+		externalID := *p.ExternalID
+		p.ExternalID = &externalID
+	}
+	return p
+}
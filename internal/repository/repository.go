@@ -0,0 +1,244 @@
+// Package repository отделяет хранение людей от HTTP-обработчиков
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"people-service/internal/audit"
+	"people-service/internal/dedupe"
+	"people-service/internal/models"
+)
+
+var (
+	ErrNotFound        = errors.New("person not found")
+	ErrDuplicate       = errors.New("person already exists")
+	ErrVersionConflict = errors.New("person was modified by another request")
+)
+
+// Поля, по которым DuplicateError сообщает о конфликте
+const (
+	DuplicateIdentity   = "identity"
+	DuplicateExternalID = "external_id"
+)
+
+// DuplicateError нарушение правила уникальности или занятый external_id;
+// ExistingID указывает на запись, с которой произошел конфликт
+type DuplicateError struct {
+	ExistingID uint
+	Field      string // DuplicateIdentity или DuplicateExternalID
+}
+
+func (e *DuplicateError) Error() string {
+	if e.Field == DuplicateExternalID {
+		return fmt.Sprintf("external id is already used by person %d", e.ExistingID)
+	}
+	return fmt.Sprintf("person already exists with id %d", e.ExistingID)
+}
+
+func (e *DuplicateError) Is(target error) bool {
+	return target == ErrDuplicate
+}
+
+// Page страница списка людей
+type Page struct {
+	People []models.Person
+	// Total общее число совпадений с фильтром; -1 при filter.SkipCount
+	Total      int64
+	NextCursor string // Пусто, если дальше записей нет
+	PrevCursor string // Пусто, если это первая страница
+}
+
+// PersonRepository хранилище записей о людях
+type PersonRepository interface {
+	// Create сохраняет нового человека и заполняет ID и временные метки;
+	// при нарушении правила уникальности возвращает *DuplicateError
+	Create(ctx context.Context, person *models.Person) error
+	// Get возвращает человека по ID или ErrNotFound
+	Get(ctx context.Context, id uint) (*models.Person, error)
+	// GetByExternalID возвращает неудаленного человека по ключу внешней
+	// системы или ErrNotFound
+	GetByExternalID(ctx context.Context, externalID string) (*models.Person, error)
+	// List возвращает страницу людей по фильтру: по номеру страницы или,
+	// если задан filter.Cursor, по ключу сортировки
+	List(ctx context.Context, filter models.PersonFilter) (*Page, error)
+	// Update сохраняет все поля существующего человека и увеличивает Version;
+	// как и Create, может вернуть *DuplicateError, а если запись изменилась
+	// после чтения person — ErrVersionConflict
+	Update(ctx context.Context, person *models.Person) error
+	// Delete помечает человека удаленным и увеличивает Version или возвращает
+	// ErrNotFound
+	Delete(ctx context.Context, id uint) error
+	// Stats считает распределения неудаленных людей, подходящих под фильтр
+	// (пагинация и сортировка фильтра не учитываются), с возрастными группами
+	// ширины bucketWidth
+	Stats(ctx context.Context, filter models.PersonFilter, bucketWidth int) (*models.PeopleStats, error)
+	// Search ищет неудаленных людей сразу по имени, фамилии и отчеству и
+	// возвращает не больше query.Limit записей от самых релевантных
+	Search(ctx context.Context, query models.SearchQuery) ([]models.SearchResult, error)
+
+	// All возвращает все неудаленные записи
+	All(ctx context.Context) ([]models.Person, error)
+	// GetMany возвращает неудаленных людей с указанными ID в порядке ID;
+	// отсутствующие ID пропускаются
+	GetMany(ctx context.Context, ids []uint) ([]models.Person, error)
+	// DuplicatePairs возвращает страницу пар неудаленных людей с оценкой
+	// dedupe.Score не ниже query.Threshold от самых похожих и сообщает, есть
+	// ли следующая страница
+	DuplicatePairs(ctx context.Context, query models.DuplicatesQuery) ([]dedupe.Pair, bool, error)
+	// Merge в одной транзакции помечает удаленными записи merged, сохраняет
+	// survivor и пишет журнал слияния с выбором полей fields. Как и Update,
+	// возвращает ErrVersionConflict, если survivor или любая из записей merged
+	// изменилась после чтения
+	Merge(ctx context.Context, survivor *models.Person, merged []models.Person, fields models.RawJSON) ([]models.PersonMerge, error)
+	// Merges возвращает журнал слияний в запись survivorID
+	Merges(ctx context.Context, survivorID uint) ([]models.PersonMerge, error)
+
+	// ListDeleted возвращает страницу удаленных людей по фильтру,
+	// начиная с удаленных последними
+	ListDeleted(ctx context.Context, filter models.PersonFilter) ([]models.Person, int64, error)
+	// Restore снимает пометку удаления; ErrNotFound, если запись не удалена,
+	// *DuplicateError, если ее имя уже занято
+	Restore(ctx context.Context, id uint) (*models.Person, error)
+	// Purge физически удаляет человека, удаленного или нет, вместе с его
+	// историей и журналом слияний в него. Записи о его слиянии в другого
+	// человека остаются в журнале того: в них есть снимок
+	Purge(ctx context.Context, id uint) error
+	// PurgeDeleted физически удаляет людей, помеченных удаленными раньше before,
+	// так же, как Purge, и возвращает их число
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+
+	// History возвращает версии человека, включая удаленного, от первой
+	// к последней или ErrNotFound, если истории нет
+	History(ctx context.Context, personID uint) ([]models.PersonVersion, error)
+	// HistoryMany возвращает версии нескольких людей одним запросом:
+	// ID человека -> версии от первой к последней; люди без истории пропускаются
+	HistoryMany(ctx context.Context, personIDs []uint) (map[uint][]models.PersonVersion, error)
+	// Version возвращает одну версию или ErrNotFound
+	Version(ctx context.Context, personID uint, version int) (*models.PersonVersion, error)
+	// Revert возвращает неудаленному человеку поля из версии version,
+	// записывая это как новую версию
+	Revert(ctx context.Context, personID uint, version int) (*models.Person, error)
+}
+
+// newVersion строит запись истории для after с номером after.Version; автор
+// и ID запроса берутся из ctx
+func newVersion(ctx context.Context, op models.VersionOperation, before, after *models.Person) (models.PersonVersion, error) {
+	snapshot, err := json.Marshal(after)
+	if err != nil {
+		return models.PersonVersion{}, err
+	}
+	diff, err := json.Marshal(models.DiffPeople(before, after))
+	if err != nil {
+		return models.PersonVersion{}, err
+	}
+
+	meta := audit.FromContext(ctx)
+	return models.PersonVersion{
+		PersonID:  after.ID,
+		Version:   after.Version,
+		Operation: op,
+		Snapshot:  models.RawJSON(snapshot),
+		Diff:      models.RawJSON(diff),
+		Actor:     meta.Actor,
+		RequestID: meta.RequestID,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// newMerge строит запись журнала слияния: merged — запись в момент удаления
+func newMerge(survivorID uint, merged *models.Person, fields models.RawJSON, at time.Time) (models.PersonMerge, error) {
+	snapshot, err := json.Marshal(merged)
+	if err != nil {
+		return models.PersonMerge{}, err
+	}
+	return models.PersonMerge{
+		SurvivorID: survivorID,
+		MergedID:   merged.ID,
+		Snapshot:   models.RawJSON(snapshot),
+		Fields:     fields,
+		CreatedAt:  at,
+	}, nil
+}
+
+// listQuery разобранные параметры вывода страницы
+type listQuery struct {
+	sort   []models.SortField
+	cursor *models.Cursor
+	offset int
+	limit  int
+}
+
+// parseListQuery разбирает сортировку, курсор и страницу из фильтра;
+// при выводе по курсору смещение не используется
+func parseListQuery(filter models.PersonFilter) (listQuery, error) {
+	sort, err := models.ParseSort(filter.Sort)
+	if err != nil {
+		return listQuery{}, err
+	}
+	q := listQuery{sort: sort}
+	q.offset, q.limit = pagination(filter)
+
+	if filter.Cursor != "" {
+		cursor, err := models.DecodeCursor(filter.Cursor, sort)
+		if err != nil {
+			return listQuery{}, err
+		}
+		q.cursor, q.offset = &cursor, 0
+	}
+	return q, nil
+}
+
+// reversed сообщает, что записи выбираются в обратном порядке, от курсора назад
+func (q listQuery) reversed() bool {
+	return q.cursor != nil && q.cursor.Prev
+}
+
+// page собирает страницу из people, выбранных с лимитом q.limit+1 в порядке
+// выборки, и строит курсоры соседних страниц
+func (q listQuery) page(people []models.Person, total int64) *Page {
+	more := len(people) > q.limit
+	if more {
+		people = people[:q.limit]
+	}
+	if q.reversed() {
+		slices.Reverse(people)
+	}
+
+	page := &Page{People: people, Total: total}
+	if len(people) == 0 {
+		return page
+	}
+	first, last := people[0], people[len(people)-1]
+
+	hasNext, hasPrev := more, q.offset > 0
+	switch {
+	case q.reversed():
+		hasNext, hasPrev = true, more
+	case q.cursor != nil:
+		hasPrev = true
+	}
+	if hasNext {
+		page.NextCursor = models.NewCursor(last, q.sort, false).Encode()
+	}
+	if hasPrev {
+		page.PrevCursor = models.NewCursor(first, q.sort, true).Encode()
+	}
+	return page
+}
+
+// pagination возвращает смещение и размер страницы с учетом значений по умолчанию
+func pagination(filter models.PersonFilter) (offset, limit int) {
+	page, limit := filter.Page, filter.Limit
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+	return (page - 1) * limit, limit
+}
@@ -0,0 +1,871 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"people-service/internal/audit"
+	"people-service/internal/config"
+	"people-service/internal/db"
+	"people-service/internal/models"
+	"people-service/internal/repository"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// forEachRepository прогоняет тест на всех реализациях хранилища;
+// GormRepository проверяется на SQLite в памяти
+func forEachRepository(t *testing.T, test func(t *testing.T, repo repository.PersonRepository)) {
+	t.Run("memory", func(t *testing.T) {
+		test(t, repository.NewMemoryRepository(models.IdentityFullName))
+	})
+	t.Run("sqlite", func(t *testing.T) {
+		test(t, newSQLiteRepository(t))
+	})
+}
+
+func newSQLiteRepository(t *testing.T) *repository.GormRepository {
+	t.Helper()
+	return repository.NewGormRepository(newSQLiteDB(t), models.IdentityFullName)
+}
+
+// newSQLiteDB открывает SQLite в памяти и применяет миграции
+func newSQLiteDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	conn, err := db.InitDB(config.DBConfig{Driver: db.DriverSQLite, SQLitePath: ":memory:"})
+	if err != nil {
+		t.Fatalf("InitDB() error = %v", err)
+	}
+	sqlDB, err := conn.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	migrator, err := db.NewMigrator(sqlDB, db.DriverSQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("migrations failed: %v", err)
+	}
+	return conn
+}
+
+func seed(t *testing.T, repo repository.PersonRepository, people ...models.Person) []models.Person {
+	t.Helper()
+	for i := range people {
+		if err := repo.Create(context.Background(), &people[i]); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+	return people
+}
+
+func TestRepositoryList(t *testing.T) {
+	forEachRepository(t, testList)
+}
+
+func testList(t *testing.T, repo repository.PersonRepository) {
+	patronymic := "Olegovich"
+	seed(t, repo,
+		models.Person{Name: "Dmitriy", Surname: "Ushakov", Patronymic: &patronymic, Age: 42, Gender: "male", Nationality: "RU"},
+		models.Person{Name: "Anna", Surname: "Ivanova", Age: 31, Gender: "female", Nationality: "UA"},
+		models.Person{Name: "Dmitry", Surname: "Petrov", Age: 42, Gender: "male", Nationality: "RU"},
+		models.Person{Name: "Дмитрий", Surname: "Ушаков", Age: 50, Gender: "male", Nationality: "RU"},
+		models.Person{Name: "Oleg", Surname: "Ivanov"},
+	)
+	age := func(n int) *int { return &n }
+	hourAgo := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name      string
+		filter    models.PersonFilter
+		wantTotal int64
+		wantLen   int
+	}{
+		{"no filter", models.PersonFilter{}, 5, 5},
+		{"name substring case-insensitive", models.PersonFilter{Name: "dmitr"}, 2, 2},
+		{"cyrillic case-insensitive", models.PersonFilter{Surname: "УШАК"}, 1, 1},
+		{"prefix", models.PersonFilter{Surname: "ivanov", Match: models.MatchPrefix}, 2, 2},
+		{"exact", models.PersonFilter{Surname: "IVANOV", Match: models.MatchExact}, 1, 1},
+		{"patronymic", models.PersonFilter{Patronymic: "oleg"}, 1, 1},
+		{"translit", models.PersonFilter{Name: "dmitriy", Match: models.MatchTranslit}, 2, 2},
+		{"phonetic", models.PersonFilter{Name: "Dmitriy", Match: models.MatchPhonetic}, 3, 3},
+		{"phonetic cyrillic", models.PersonFilter{Surname: "Ушакофф", Match: models.MatchPhonetic}, 2, 2},
+		{"phonetic patronymic", models.PersonFilter{Patronymic: "Olegovitch", Match: models.MatchPhonetic}, 1, 1},
+		{"age", models.PersonFilter{Age: age(31)}, 1, 1},
+		{"age zero", models.PersonFilter{Age: age(0)}, 1, 1},
+		{"age range", models.PersonFilter{AgeMin: age(40), AgeMax: age(45)}, 2, 2},
+		{"gender normalized", models.PersonFilter{Gender: []string{"MALE"}}, 3, 3},
+		{"gender set", models.PersonFilter{Gender: []string{"male,female"}}, 4, 4},
+		{"nationality set", models.PersonFilter{Nationality: []string{"ua", "ru"}}, 4, 4},
+		{"created range", models.PersonFilter{CreatedFrom: hourAgo}, 5, 5},
+		{"created before", models.PersonFilter{CreatedTo: hourAgo}, 0, 0},
+		{"updated range", models.PersonFilter{UpdatedFrom: hourAgo, UpdatedTo: time.Now().Add(time.Hour)}, 5, 5},
+		{"is null", models.PersonFilter{IsNull: []string{"patronymic"}}, 4, 4},
+		{"is null several", models.PersonFilter{IsNull: []string{"gender,nationality"}}, 1, 1},
+		{"pagination", models.PersonFilter{Page: 2, Limit: 3}, 5, 2},
+		{"page past end", models.PersonFilter{Page: 5, Limit: 2}, 5, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := repo.List(context.Background(), tt.filter)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if page.Total != tt.wantTotal || len(page.People) != tt.wantLen {
+				t.Errorf("List() = %d records of %d, want %d of %d", len(page.People), page.Total, tt.wantLen, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestRepositorySort(t *testing.T) {
+	forEachRepository(t, testSort)
+}
+
+func testSort(t *testing.T, repo repository.PersonRepository) {
+	seed(t, repo,
+		models.Person{Name: "Anna", Surname: "Ivanova", Age: 31},
+		models.Person{Name: "Boris", Surname: "Petrov", Age: 42},
+		models.Person{Name: "Oleg", Surname: "Ivanov", Age: 42},
+		models.Person{Name: "Dmitry", Surname: "Petrov", Age: 25},
+	)
+
+	tests := []struct {
+		sort string
+		want []string
+	}{
+		{"", []string{"Dmitry", "Oleg", "Boris", "Anna"}},
+		{"name", []string{"Anna", "Boris", "Dmitry", "Oleg"}},
+		{"-age,name", []string{"Boris", "Oleg", "Anna", "Dmitry"}},
+		{"surname", []string{"Oleg", "Anna", "Boris", "Dmitry"}},
+		{"-surname", []string{"Dmitry", "Boris", "Anna", "Oleg"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			page, err := repo.List(context.Background(), models.PersonFilter{Sort: tt.sort})
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			names := personNames(page.People)
+			if strings.Join(names, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("List(sort=%q) = %v, want %v", tt.sort, names, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepositorySearch(t *testing.T) {
+	forEachRepository(t, testSearch)
+}
+
+func testSearch(t *testing.T, repo repository.PersonRepository) {
+	patronymic := "Olegovich"
+	people := seed(t, repo,
+		models.Person{Name: "Dmitriy", Surname: "Ushakov", Patronymic: &patronymic},
+		models.Person{Name: "Anna", Surname: "Ushakova"},
+		models.Person{Name: "Дмитрий", Surname: "Ушаков"},
+		models.Person{Name: "Dmitriy", Surname: "Petrov"},
+	)
+	if err := repo.Delete(context.Background(), people[3].ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		query     models.SearchQuery
+		want      []string
+		highlight string
+	}{
+		{"whole words", models.SearchQuery{Q: "ushakov"}, []string{"Dmitriy"}, "Dmitriy <mark>Ushakov</mark> Olegovich"},
+		{"all words required", models.SearchQuery{Q: "ДМИТРИЙ ушаков"}, []string{"Дмитрий"}, "<mark>Дмитрий</mark> <mark>Ушаков</mark>"},
+		{"deleted excluded", models.SearchQuery{Q: "petrov"}, []string{}, ""},
+		{"trigram", models.SearchQuery{Q: "ushakof", Mode: models.SearchTrigram}, []string{"Dmitriy", "Дмитрий", "Anna"}, "Dmitriy <mark>Ushakov</mark> Olegovich"},
+		{"trigram limit", models.SearchQuery{Q: "ushakof", Mode: models.SearchTrigram, Limit: 1}, []string{"Dmitriy"}, "Dmitriy <mark>Ushakov</mark> Olegovich"},
+		{"no match", models.SearchQuery{Q: "nobody"}, []string{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.query.Normalize(10, 0)
+			results, err := repo.Search(context.Background(), tt.query)
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			names := make([]string, len(results))
+			for i, r := range results {
+				names[i] = r.Name
+			}
+			if strings.Join(names, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("Search(%q) = %v, want %v", tt.query.Q, names, tt.want)
+			}
+			if len(results) > 0 && results[0].Highlight != tt.highlight {
+				t.Errorf("Search(%q) highlight = %q, want %q", tt.query.Q, results[0].Highlight, tt.highlight)
+			}
+		})
+	}
+}
+
+func personNames(people []models.Person) []string {
+	names := make([]string, len(people))
+	for i, p := range people {
+		names[i] = p.Name
+	}
+	return names
+}
+
+func TestRepositoryCursor(t *testing.T) {
+	forEachRepository(t, testCursor)
+}
+
+func testCursor(t *testing.T, repo repository.PersonRepository) {
+	ctx := context.Background()
+	seed(t, repo,
+		models.Person{Name: "Anna", Surname: "Ivanova", Age: 30},
+		models.Person{Name: "Boris", Surname: "Petrov", Age: 30},
+		models.Person{Name: "Dmitry", Surname: "Sidorov", Age: 25},
+		models.Person{Name: "Oleg", Surname: "Ivanov", Age: 40},
+		models.Person{Name: "Pavel", Surname: "Orlov", Age: 30},
+	)
+	filter := models.PersonFilter{Sort: "-age", Limit: 2, SkipCount: true}
+
+	var pages [][]string
+	var last *repository.Page
+	for {
+		page, err := repo.List(ctx, filter)
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		if page.Total != -1 {
+			t.Errorf("List() with SkipCount total = %d, want -1", page.Total)
+		}
+		pages = append(pages, personNames(page.People))
+		last = page
+		if page.NextCursor == "" {
+			break
+		}
+		filter.Cursor = page.NextCursor
+	}
+
+	// Равный возраст упорядочен по id в направлении последнего поля
+	want := "[[Oleg Pavel] [Boris Anna] [Dmitry]]"
+	if got := fmt.Sprint(pages); got != want {
+		t.Fatalf("pages = %s, want %s", got, want)
+	}
+
+	// Вставка перед текущей позицией не сдвигает следующие страницы
+	seed(t, repo, models.Person{Name: "Ivan", Surname: "Kozlov", Age: 50})
+
+	filter.Cursor = last.PrevCursor
+	page, err := repo.List(ctx, filter)
+	if err != nil {
+		t.Fatalf("List(prev) error = %v", err)
+	}
+	if got := fmt.Sprint(personNames(page.People)); got != "[Boris Anna]" {
+		t.Errorf("prev page = %s, want [Boris Anna]", got)
+	}
+	if page.NextCursor == "" || page.PrevCursor == "" {
+		t.Errorf("prev page cursors = %q, %q, want both", page.NextCursor, page.PrevCursor)
+	}
+
+	filter.Cursor = page.PrevCursor
+	page, _ = repo.List(ctx, filter)
+	if got := fmt.Sprint(personNames(page.People)); got != "[Oleg Pavel]" {
+		t.Errorf("first page via prev = %s, want [Oleg Pavel]", got)
+	}
+	if page.PrevCursor == "" {
+		t.Error("page after new insert should have prev cursor")
+	}
+
+	if _, err := repo.List(ctx, models.PersonFilter{Sort: "name", Cursor: last.PrevCursor}); !errors.Is(err, models.ErrInvalidCursor) {
+		t.Errorf("List() with cursor for another sort error = %v, want ErrInvalidCursor", err)
+	}
+}
+
+func TestRepositoryDelete(t *testing.T) {
+	forEachRepository(t, testDelete)
+}
+
+func testDelete(t *testing.T, repo repository.PersonRepository) {
+	ctx := context.Background()
+	people := seed(t, repo, models.Person{Name: "Anna", Surname: "Ivanova"})
+	id := people[0].ID
+
+	if err := repo.Delete(ctx, id); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := repo.Get(ctx, id); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("Get() after delete error = %v, want ErrNotFound", err)
+	}
+	if err := repo.Delete(ctx, id); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("second Delete() error = %v, want ErrNotFound", err)
+	}
+	if page, _ := repo.List(ctx, models.PersonFilter{}); page.Total != 0 {
+		t.Errorf("List() total after delete = %d, want 0", page.Total)
+	}
+}
+
+func TestRepositoryDuplicate(t *testing.T) {
+	forEachRepository(t, testDuplicate)
+}
+
+func testDuplicate(t *testing.T, repo repository.PersonRepository) {
+	ctx := context.Background()
+	people := seed(t, repo,
+		models.Person{Name: "Anna", Surname: "Ivanova"},
+		models.Person{Name: "Anna", Surname: "Petrova"},
+	)
+
+	dupe := models.Person{Name: " anna ", Surname: "IVANOVA"}
+	var dupErr *repository.DuplicateError
+	if err := repo.Create(ctx, &dupe); !errors.As(err, &dupErr) {
+		t.Fatalf("Create() duplicate error = %v, want *DuplicateError", err)
+	}
+	if dupErr.ExistingID != people[0].ID {
+		t.Errorf("DuplicateError.ExistingID = %d, want %d", dupErr.ExistingID, people[0].ID)
+	}
+
+	renamed := people[1]
+	renamed.Surname = "Ivanova"
+	if err := repo.Update(ctx, &renamed); !errors.Is(err, repository.ErrDuplicate) {
+		t.Errorf("Update() into duplicate error = %v, want ErrDuplicate", err)
+	}
+
+	// После удаления оригинала имя снова свободно
+	if err := repo.Delete(ctx, people[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Create(ctx, &models.Person{Name: "Anna", Surname: "Ivanova"}); err != nil {
+		t.Errorf("Create() after delete error = %v", err)
+	}
+}
+
+// При заполнении ключ получает самая ранняя из копий; остальные копии
+// остаются без ключа и редактируются и сливаются, пока не меняют имя на
+// занятое
+func TestGormRepositoryIdentityBackfill(t *testing.T) {
+	ctx := context.Background()
+	conn := newSQLiteDB(t)
+	people := seed(t, repository.NewGormRepository(conn, models.IdentityNone),
+		models.Person{Name: "Anna", Surname: "Ivanova"},
+		models.Person{Name: "Anna", Surname: "Ivanova"},
+		models.Person{Name: "Oleg", Surname: "Ivanov"},
+		models.Person{Name: "Pavel", Surname: "Petrov"},
+	)
+	repo := repository.NewGormRepository(conn, models.IdentityFullName)
+	if n, err := repo.BackfillIdentityKeys(ctx); err != nil || n != 3 {
+		t.Fatalf("BackfillIdentityKeys() = %d, %v, want 3", n, err)
+	}
+
+	grandfathered, err := repo.Get(ctx, people[1].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	grandfathered.Age = 30
+	if err := repo.Update(ctx, grandfathered); err != nil {
+		t.Fatalf("Update() of grandfathered copy error = %v", err)
+	}
+	if grandfathered.IdentityKey != nil {
+		t.Errorf("IdentityKey = %q, want nil", *grandfathered.IdentityKey)
+	}
+
+	renamed := *grandfathered
+	renamed.Name = "Oleg"
+	renamed.Surname = "Ivanov"
+	if err := repo.Update(ctx, &renamed); !errors.Is(err, repository.ErrDuplicate) {
+		t.Errorf("Update() renaming into duplicate error = %v, want ErrDuplicate", err)
+	}
+
+	if _, err := repo.Merge(ctx, grandfathered, []models.Person{people[3]}, ""); err != nil {
+		t.Fatalf("Merge() into grandfathered copy error = %v", err)
+	}
+
+	if n, err := repository.NewGormRepository(conn, models.IdentityNone).BackfillIdentityKeys(ctx); err != nil || n != 2 {
+		t.Errorf("BackfillIdentityKeys() with rule none = %d, %v, want 2", n, err)
+	}
+}
+
+func TestRepositoryExternalID(t *testing.T) {
+	forEachRepository(t, testExternalID)
+}
+
+func testExternalID(t *testing.T, repo repository.PersonRepository) {
+	ctx := context.Background()
+	external := func(s string) *string { return &s }
+	people := seed(t, repo,
+		models.Person{Name: "Anna", Surname: "Ivanova", ExternalID: external("crm-1")},
+		models.Person{Name: "Oleg", Surname: "Petrov"},
+	)
+
+	found, err := repo.GetByExternalID(ctx, "crm-1")
+	if err != nil || found.ID != people[0].ID {
+		t.Fatalf("GetByExternalID() = %v, %v, want person %d", found, err, people[0].ID)
+	}
+	if _, err := repo.GetByExternalID(ctx, "crm-2"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("GetByExternalID(unknown) error = %v, want ErrNotFound", err)
+	}
+
+	taken := people[1]
+	taken.ExternalID = external("crm-1")
+	var dupErr *repository.DuplicateError
+	if err := repo.Update(ctx, &taken); !errors.As(err, &dupErr) ||
+		dupErr.ExistingID != people[0].ID || dupErr.Field != repository.DuplicateExternalID {
+		t.Fatalf("Update() with taken external id error = %v, want DuplicateError for %d", err, people[0].ID)
+	}
+
+	// Удаленная запись освобождает ключ, но не может вернуться, пока он занят
+	if err := repo.Delete(ctx, people[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.GetByExternalID(ctx, "crm-1"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("GetByExternalID(deleted) error = %v, want ErrNotFound", err)
+	}
+	reused := models.Person{Name: "Boris", Surname: "Sidorov", ExternalID: external("crm-1")}
+	if err := repo.Create(ctx, &reused); err != nil {
+		t.Fatalf("Create() with freed external id error = %v", err)
+	}
+	if _, err := repo.Restore(ctx, people[0].ID); !errors.As(err, &dupErr) || dupErr.ExistingID != reused.ID {
+		t.Errorf("Restore() error = %v, want DuplicateError for %d", err, reused.ID)
+	}
+}
+
+func TestRepositoryStats(t *testing.T) {
+	forEachRepository(t, testStats)
+}
+
+func testStats(t *testing.T, repo repository.PersonRepository) {
+	ctx := context.Background()
+	people := seed(t, repo,
+		models.Person{Name: "Anna", Surname: "Ivanova", Age: 20, Gender: "female", Nationality: "RU"},
+		models.Person{Name: "Boris", Surname: "Petrov", Age: 25, Gender: "male", Nationality: "RU"},
+		models.Person{Name: "Oleg", Surname: "Ivanov", Age: 31, Gender: "male", Nationality: "UA"},
+		models.Person{Name: "Pavel", Surname: "Sidorov", Age: 42, Gender: "male"},
+		models.Person{Name: "Dmitry", Surname: "Orlov", Age: 50, Gender: "female", Nationality: "RU"},
+		models.Person{Name: "Ivan", Surname: "Deleted", Age: 99, Gender: "male", Nationality: "KZ"},
+		models.Person{Name: "Yuri", Surname: "Unknown", Gender: "male", Nationality: "UA"},
+	)
+	if err := repo.Delete(ctx, people[5].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := repo.Stats(ctx, models.PersonFilter{}, 10)
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+	if stats.Total != 6 || stats.Age.Unknown != 1 {
+		t.Errorf("Total = %d, Age.Unknown = %d, want 6 and 1", stats.Total, stats.Age.Unknown)
+	}
+	if got := fmt.Sprint(stats.Gender); got != "[{male 4} {female 2}]" {
+		t.Errorf("Gender = %s", got)
+	}
+	if got := fmt.Sprint(stats.Nationality); got != "[{RU 3} {UA 2} { 1}]" {
+		t.Errorf("Nationality = %s", got)
+	}
+	if got := fmt.Sprint(stats.AgeBuckets); got != "[{20 30 2} {30 40 1} {40 50 1} {50 60 1}]" {
+		t.Errorf("AgeBuckets = %s", got)
+	}
+
+	age := stats.Age
+	for name, tt := range map[string]struct {
+		got  *float64
+		want float64
+	}{
+		"average": {age.Average, 33.6},
+		"p25":     {age.P25, 25},
+		"median":  {age.Median, 31},
+		"p75":     {age.P75, 42},
+		"p90":     {age.P90, 46.8},
+		"p99":     {age.P99, 49.68},
+	} {
+		if tt.got == nil || math.Abs(*tt.got-tt.want) > 1e-9 {
+			t.Errorf("Age.%s = %v, want %v", name, tt.got, tt.want)
+		}
+	}
+	if age.Min == nil || *age.Min != 20 || age.Max == nil || *age.Max != 50 {
+		t.Errorf("Age min/max = %v/%v, want 20/50", age.Min, age.Max)
+	}
+
+	minAge := 25
+	stats, err = repo.Stats(ctx, models.PersonFilter{Gender: []string{"male"}, AgeMin: &minAge}, 25)
+	if err != nil {
+		t.Fatalf("Stats(filter) error = %v", err)
+	}
+	if got := fmt.Sprint(stats.AgeBuckets); stats.Total != 3 || got != "[{25 50 3}]" {
+		t.Errorf("Stats(male) = %d records, buckets %s", stats.Total, got)
+	}
+
+	stats, err = repo.Stats(ctx, models.PersonFilter{Name: "nobody"}, 10)
+	if err != nil {
+		t.Fatalf("Stats(empty) error = %v", err)
+	}
+	if stats.Total != 0 || len(stats.Gender) != 0 || len(stats.AgeBuckets) != 0 || stats.Age.Average != nil || stats.Age.Median != nil {
+		t.Errorf("Stats(empty) = %+v", stats)
+	}
+}
+
+func TestRepositoryDuplicatePairs(t *testing.T) {
+	forEachRepository(t, testDuplicatePairs)
+}
+
+func testDuplicatePairs(t *testing.T, repo repository.PersonRepository) {
+	ctx := context.Background()
+	olegovich := "Olegovich"
+	people := seed(t, repo,
+		models.Person{Name: "Dmitriy", Surname: "Ushakov", Patronymic: &olegovich},
+		models.Person{Name: "Anna", Surname: "Ivanova"},
+		models.Person{Name: "Dmitry", Surname: "Ushakov"},
+		models.Person{Name: "Дмитрий", Surname: "Ушаков"},
+		models.Person{Name: "Boris", Surname: "Ushakov"},
+		models.Person{Name: "Anna", Surname: "Ivanova-Petrova"},
+	)
+	if err := repo.Delete(ctx, people[5].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	query := models.DuplicatesQuery{Threshold: 0.6}
+	query.Paginate(2, 0)
+	first, more, err := repo.DuplicatePairs(ctx, query)
+	if err != nil {
+		t.Fatalf("DuplicatePairs() error = %v", err)
+	}
+	if len(first) != 2 || !more {
+		t.Fatalf("DuplicatePairs() page 1 = %+v, more = %v, want 2 pairs and more", first, more)
+	}
+	// Транслитерация совпадает полностью, оценка максимальна
+	if first[0].A != people[0].ID || first[0].B != people[3].ID || math.Abs(first[0].Score-1) > 1e-6 {
+		t.Errorf("DuplicatePairs() best pair = %+v, want %d-%d with score 1", first[0], people[0].ID, people[3].ID)
+	}
+
+	query.Page = 2
+	second, more, err := repo.DuplicatePairs(ctx, query)
+	if err != nil {
+		t.Fatalf("DuplicatePairs() error = %v", err)
+	}
+	if len(second) != 1 || more {
+		t.Fatalf("DuplicatePairs() page 2 = %+v, more = %v, want 1 pair and no more", second, more)
+	}
+	for _, pair := range append(first, second...) {
+		for _, id := range []uint{people[1].ID, people[4].ID, people[5].ID} {
+			if pair.A == id || pair.B == id {
+				t.Errorf("DuplicatePairs() pair %+v contains unrelated person %d", pair, id)
+			}
+		}
+		if pair.Score < query.Threshold {
+			t.Errorf("DuplicatePairs() pair %+v is below threshold", pair)
+		}
+	}
+
+	got, err := repo.GetMany(ctx, []uint{people[3].ID, people[0].ID, people[5].ID})
+	if err != nil || len(got) != 2 || got[0].ID != people[0].ID || got[1].ID != people[3].ID {
+		t.Errorf("GetMany() = %+v, %v, want people %d and %d", got, err, people[0].ID, people[3].ID)
+	}
+}
+
+func TestRepositoryMerge(t *testing.T) {
+	forEachRepository(t, testMerge)
+}
+
+func testMerge(t *testing.T, repo repository.PersonRepository) {
+	ctx := context.Background()
+	people := seed(t, repo,
+		models.Person{Name: "Dmitriy", Surname: "Ushakov", Age: 42},
+		models.Person{Name: "Dmitry", Surname: "Ushakov", Age: 43},
+	)
+
+	// Survivor забирает имя удаляемого дубликата
+	survivor := people[0]
+	survivor.Name = "Dmitry"
+	merges, err := repo.Merge(ctx, &survivor, people[1:], `{"name":2}`)
+	if err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	if len(merges) != 1 || !strings.Contains(string(merges[0].Snapshot), `"name":"Dmitry"`) {
+		t.Errorf("Merge() log = %+v, want snapshot of the merged record", merges)
+	}
+
+	if _, err := repo.Get(ctx, people[1].ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("Get() merged record error = %v, want ErrNotFound", err)
+	}
+	got, err := repo.Get(ctx, survivor.ID)
+	if err != nil || got.Name != "Dmitry" {
+		t.Errorf("Get() survivor = %+v, %v", got, err)
+	}
+
+	log, err := repo.Merges(ctx, survivor.ID)
+	if err != nil || len(log) != 1 || log[0].MergedID != people[1].ID {
+		t.Errorf("Merges() = %+v, %v", log, err)
+	}
+
+	if _, err := repo.Merge(ctx, &survivor, people[1:], ""); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("Merge() of deleted record error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestRepositoryPurgeKeepsMergeLog(t *testing.T) {
+	forEachRepository(t, testPurgeKeepsMergeLog)
+}
+
+func testPurgeKeepsMergeLog(t *testing.T, repo repository.PersonRepository) {
+	ctx := context.Background()
+	people := seed(t, repo,
+		models.Person{Name: "Dmitriy", Surname: "Ushakov"},
+		models.Person{Name: "Dmitry", Surname: "Ushakov"},
+		models.Person{Name: "Дмитрий", Surname: "Ушаков"},
+	)
+	survivor := people[0]
+	if _, err := repo.Merge(ctx, &survivor, people[1:], ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// Слитые записи удалены: одну стирает администратор, другую очистка по сроку
+	if err := repo.Purge(ctx, people[1].ID); err != nil {
+		t.Fatalf("Purge() error = %v", err)
+	}
+	if purged, err := repo.PurgeDeleted(ctx, time.Now().Add(time.Minute)); err != nil || purged != 1 {
+		t.Fatalf("PurgeDeleted() = %d, %v, want 1", purged, err)
+	}
+	if log, err := repo.Merges(ctx, survivor.ID); err != nil || len(log) != 2 {
+		t.Errorf("Merges() after purge = %+v, %v, want 2 entries", log, err)
+	}
+
+	if err := repo.Purge(ctx, survivor.ID); err != nil {
+		t.Fatalf("Purge() of survivor error = %v", err)
+	}
+	if log, err := repo.Merges(ctx, survivor.ID); err != nil || len(log) != 0 {
+		t.Errorf("Merges() after survivor purge = %+v, %v, want none", log, err)
+	}
+}
+
+func TestRepositoryMergeVersionConflict(t *testing.T) {
+	forEachRepository(t, testMergeVersionConflict)
+}
+
+func testMergeVersionConflict(t *testing.T, repo repository.PersonRepository) {
+	ctx := context.Background()
+	people := seed(t, repo,
+		models.Person{Name: "Dmitriy", Surname: "Ushakov", Age: 42},
+		models.Person{Name: "Dmitry", Surname: "Ushakov", Age: 43},
+	)
+
+	// Дубликат изменили после того, как его прочитал клиент слияния
+	edited := people[1]
+	edited.Age = 44
+	if err := repo.Update(ctx, &edited); err != nil {
+		t.Fatal(err)
+	}
+
+	survivor := people[0]
+	if _, err := repo.Merge(ctx, &survivor, people[1:], ""); !errors.Is(err, repository.ErrVersionConflict) {
+		t.Fatalf("Merge() of stale record error = %v, want ErrVersionConflict", err)
+	}
+	if got, err := repo.Get(ctx, edited.ID); err != nil || got.Age != 44 {
+		t.Errorf("Get() edited record = %+v, %v, want it kept", got, err)
+	}
+	if got, err := repo.Get(ctx, survivor.ID); err != nil || got.Version != survivor.Version {
+		t.Errorf("Get() survivor = %+v, %v, want it unchanged", got, err)
+	}
+}
+
+func TestRepositoryRestoreAndPurge(t *testing.T) {
+	forEachRepository(t, testRestoreAndPurge)
+}
+
+func testRestoreAndPurge(t *testing.T, repo repository.PersonRepository) {
+	ctx := context.Background()
+	people := seed(t, repo,
+		models.Person{Name: "Anna", Surname: "Ivanova"},
+		models.Person{Name: "Boris", Surname: "Petrov"},
+	)
+
+	if _, err := repo.Restore(ctx, people[0].ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("Restore() of live record error = %v, want ErrNotFound", err)
+	}
+
+	for _, p := range people {
+		if err := repo.Delete(ctx, p.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	deleted, total, err := repo.ListDeleted(ctx, models.PersonFilter{Name: "ann"})
+	if err != nil || total != 1 || deleted[0].ID != people[0].ID {
+		t.Fatalf("ListDeleted() = %+v, %d, %v", deleted, total, err)
+	}
+
+	// Имя удаленной записи заняли, восстановить ее нельзя
+	seed(t, repo, models.Person{Name: "Anna", Surname: "Ivanova"})
+	if _, err := repo.Restore(ctx, people[0].ID); !errors.Is(err, repository.ErrDuplicate) {
+		t.Errorf("Restore() into duplicate error = %v, want ErrDuplicate", err)
+	}
+
+	restored, err := repo.Restore(ctx, people[1].ID)
+	if err != nil || restored.DeletedAt.Valid {
+		t.Fatalf("Restore() = %+v, %v", restored, err)
+	}
+	if _, err := repo.Get(ctx, people[1].ID); err != nil {
+		t.Errorf("Get() after restore error = %v", err)
+	}
+
+	purged, err := repo.PurgeDeleted(ctx, time.Now().Add(time.Minute))
+	if err != nil || purged != 1 {
+		t.Errorf("PurgeDeleted() = %d, %v, want 1", purged, err)
+	}
+	if _, total, _ := repo.ListDeleted(ctx, models.PersonFilter{}); total != 0 {
+		t.Errorf("ListDeleted() total after purge = %d, want 0", total)
+	}
+
+	if err := repo.Purge(ctx, people[1].ID); err != nil {
+		t.Errorf("Purge() error = %v", err)
+	}
+	if err := repo.Purge(ctx, people[1].ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("second Purge() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestRepositoryHistory(t *testing.T) {
+	forEachRepository(t, testHistory)
+}
+
+func testHistory(t *testing.T, repo repository.PersonRepository) {
+	ctx := audit.WithMeta(context.Background(), audit.Meta{Actor: "alice", RequestID: "req-1"})
+	person := models.Person{Name: "Anna", Surname: "Ivanova", Age: 30}
+	if err := repo.Create(ctx, &person); err != nil {
+		t.Fatal(err)
+	}
+	person.Age = 31
+	if err := repo.Update(ctx, &person); err != nil {
+		t.Fatal(err)
+	}
+
+	reverted, err := repo.Revert(ctx, person.ID, 1)
+	if err != nil || reverted.Age != 30 {
+		t.Fatalf("Revert() = %+v, %v", reverted, err)
+	}
+	if err := repo.Delete(ctx, person.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	versions, err := repo.History(ctx, person.ID)
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	wantOps := []models.VersionOperation{models.VersionCreate, models.VersionUpdate, models.VersionRevert, models.VersionDelete}
+	if len(versions) != len(wantOps) {
+		t.Fatalf("History() = %d versions, want %d", len(versions), len(wantOps))
+	}
+	for i, v := range versions {
+		if v.Version != i+1 || v.Operation != wantOps[i] || v.Actor != "alice" || v.RequestID != "req-1" {
+			t.Errorf("version %d = %+v", i+1, v)
+		}
+	}
+	if string(versions[1].Diff) != `{"age":{"old":30,"new":31}}` {
+		t.Errorf("update diff = %s", versions[1].Diff)
+	}
+
+	if _, err := repo.Version(ctx, person.ID, 9); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("Version() missing error = %v, want ErrNotFound", err)
+	}
+	if _, err := repo.Revert(ctx, person.ID, 1); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("Revert() of deleted record error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestRepositoryVersionMatchesHistory(t *testing.T) {
+	forEachRepository(t, testVersionMatchesHistory)
+}
+
+func testVersionMatchesHistory(t *testing.T, repo repository.PersonRepository) {
+	ctx := context.Background()
+	people := seed(t, repo, models.Person{Name: "Anna", Surname: "Ivanova"})
+	people[0].Age = 30
+	if err := repo.Update(ctx, &people[0]); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Delete(ctx, people[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := repo.Restore(ctx, people[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	versions, err := repo.History(ctx, restored.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := versions[len(versions)-1]
+	if restored.Version != 4 || last.Version != restored.Version || last.Operation != models.VersionRestore {
+		t.Errorf("Restore() version = %d, last history version = %+v, want both 4", restored.Version, last)
+	}
+}
+
+func TestRepositoryHistoryMany(t *testing.T) {
+	forEachRepository(t, testHistoryMany)
+}
+
+func testHistoryMany(t *testing.T, repo repository.PersonRepository) {
+	ctx := context.Background()
+	people := seed(t, repo,
+		models.Person{Name: "Anna", Surname: "Ivanova", Enrichment: `{"age":{"value":"30"}}`},
+		models.Person{Name: "Boris", Surname: "Petrov"},
+		models.Person{Name: "Vera", Surname: "Sidorova"},
+	)
+	people[0].Age = 31
+	if err := repo.Update(ctx, &people[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	history, err := repo.HistoryMany(ctx, []uint{people[0].ID, people[1].ID, 999})
+	if err != nil {
+		t.Fatalf("HistoryMany() error = %v", err)
+	}
+	if len(history) != 2 || len(history[people[0].ID]) != 2 || len(history[people[1].ID]) != 1 {
+		t.Fatalf("HistoryMany() = %+v", history)
+	}
+	for i, v := range history[people[0].ID] {
+		if v.PersonID != people[0].ID || v.Version != i+1 {
+			t.Errorf("version %d = %+v", i+1, v)
+		}
+	}
+
+	stored, err := repo.Get(ctx, people[0].ID)
+	if err != nil || stored.Enrichment != people[0].Enrichment {
+		t.Errorf("Get() enrichment = %q, %v, want it kept after Update", stored.Enrichment, err)
+	}
+}
+
+func TestRepositoryVersionConflict(t *testing.T) {
+	forEachRepository(t, testVersionConflict)
+}
+
+func testVersionConflict(t *testing.T, repo repository.PersonRepository) {
+	ctx := context.Background()
+	people := seed(t, repo, models.Person{Name: "Anna", Surname: "Ivanova"})
+	if people[0].Version != 1 {
+		t.Fatalf("Create() version = %d, want 1", people[0].Version)
+	}
+
+	first, _ := repo.Get(ctx, people[0].ID)
+	second, _ := repo.Get(ctx, people[0].ID)
+
+	first.Age = 30
+	if err := repo.Update(ctx, first); err != nil || first.Version != 2 {
+		t.Fatalf("Update() = version %d, %v", first.Version, err)
+	}
+
+	second.Age = 40
+	if err := repo.Update(ctx, second); !errors.Is(err, repository.ErrVersionConflict) {
+		t.Errorf("stale Update() error = %v, want ErrVersionConflict", err)
+	}
+	if got, _ := repo.Get(ctx, people[0].ID); got.Age != 30 || got.Version != 2 {
+		t.Errorf("Get() after stale update = age %d, version %d", got.Age, got.Version)
+	}
+}
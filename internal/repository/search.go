@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"people-service/internal/models"
+	"people-service/internal/names"
+)
+
+// trigramThreshold минимальное сходство в режиме trigram, как
+// pg_trgm.similarity_threshold по умолчанию
+const trigramThreshold = 0.3
+
+// fullName имя, фамилия и отчество через пробел, как колонка full_name в Postgres
+func fullName(p models.Person) string {
+	s := p.Name + " " + p.Surname
+	if p.Patronymic != nil && *p.Patronymic != "" {
+		s += " " + *p.Patronymic
+	}
+	return s
+}
+
+// searchTerms разбивает строку на слова в нижнем регистре
+func searchTerms(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// termScore оценивает совпадение слова имени word со словом запроса term:
+// в режиме fts нужно точное совпадение, в режиме trigram достаточно сходства
+func termScore(word, term, mode string) float64 {
+	if mode == models.SearchTrigram {
+		return names.Similarity(word, term)
+	}
+	if word == term {
+		return 1
+	}
+	return 0
+}
+
+// searchPeople ранжирует people в Go: в режиме fts каждое слово запроса
+// должно встретиться в полном имени, в режиме trigram среднее сходство слов
+// запроса с ближайшими словами имени должно быть не ниже trigramThreshold
+func searchPeople(people []models.Person, query models.SearchQuery) []models.SearchResult {
+	terms := searchTerms(query.Q)
+	if len(terms) == 0 {
+		return []models.SearchResult{}
+	}
+	threshold := 1.0
+	if query.Mode == models.SearchTrigram {
+		threshold = trigramThreshold
+	}
+
+	results := []models.SearchResult{}
+	for _, p := range people {
+		full := fullName(p)
+		words := searchTerms(full)
+
+		rank, matched := 0.0, true
+		for _, term := range terms {
+			best := 0.0
+			for _, word := range words {
+				best = max(best, termScore(word, term, query.Mode))
+			}
+			if query.Mode == models.SearchFullText && best < threshold {
+				matched = false
+				break
+			}
+			rank += best
+		}
+		rank /= float64(len(terms))
+		if !matched || rank < threshold {
+			continue
+		}
+
+		results = append(results, models.SearchResult{
+			Person: p,
+			Rank:   rank,
+			Highlight: highlight(full, func(word string) bool {
+				return slices.ContainsFunc(terms, func(term string) bool {
+					return termScore(word, term, query.Mode) >= threshold
+				})
+			}),
+		})
+	}
+
+	return topResults(results, query.Limit)
+}
+
+// topResults упорядочивает results по убыванию ранга и оставляет первые
+// limit (все, если limit не задан)
+func topResults(results []models.SearchResult, limit int) []models.SearchResult {
+	slices.SortFunc(results, func(a, b models.SearchResult) int {
+		return cmp.Or(cmp.Compare(b.Rank, a.Rank), cmp.Compare(a.ID, b.ID))
+	})
+	if len(results) > limit && limit > 0 {
+		results = results[:limit]
+	}
+	return results
+}
+
+// highlight оборачивает в теги подсветки слова text, для которых match
+// (получающий слово в нижнем регистре) возвращает true
+func highlight(text string, match func(word string) bool) string {
+	var b strings.Builder
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		if !unicode.IsLetter(runes[i]) && !unicode.IsDigit(runes[i]) {
+			b.WriteRune(runes[i])
+			i++
+			continue
+		}
+		j := i
+		for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j])) {
+			j++
+		}
+		word := string(runes[i:j])
+		if match(strings.ToLower(word)) {
+			b.WriteString(models.HighlightStart + word + models.HighlightStop)
+		} else {
+			b.WriteString(word)
+		}
+		i = j
+	}
+	return b.String()
+}
+
+// ftsMatchExpression строит запрос FTS5 из слов запроса: каждое слово
+// в кавычках, все слова обязательны
+func ftsMatchExpression(q string) string {
+	terms := searchTerms(q)
+	for i, term := range terms {
+		terms[i] = `"` + term + `"`
+	}
+	return strings.Join(terms, " ")
+}
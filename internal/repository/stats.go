@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"cmp"
+	"math"
+	"slices"
+
+	"people-service/internal/models"
+)
+
+// percentilePosition возвращает для процентиля level среди n упорядоченных
+// значений индекс нижнего соседа и долю пути к верхнему, как percentile_cont
+func percentilePosition(level float64, n int) (int, float64) {
+	pos := level * float64(n-1)
+	lower := math.Floor(pos)
+	return int(lower), pos - lower
+}
+
+// interpolate возвращает значение между neighbors[0] и neighbors[1]
+// (если верхний сосед есть) на доле пути fraction
+func interpolate(neighbors []int, fraction float64) float64 {
+	value := float64(neighbors[0])
+	if len(neighbors) > 1 {
+		value += fraction * float64(neighbors[1]-neighbors[0])
+	}
+	return value
+}
+
+// fillAgeBuckets строит возрастные группы от наименьшей до наибольшей
+// непустой, добавляя пропущенные группы с нулевым числом записей
+func fillAgeBuckets(counts map[int]int64, width int) []models.AgeBucket {
+	buckets := []models.AgeBucket{}
+	if len(counts) == 0 {
+		return buckets
+	}
+	starts := make([]int, 0, len(counts))
+	for start := range counts {
+		starts = append(starts, start)
+	}
+	first, last := slices.Min(starts), slices.Max(starts)
+	for from := first; from <= last; from += width {
+		buckets = append(buckets, models.AgeBucket{From: from, To: from + width, Count: counts[from]})
+	}
+	return buckets
+}
+
+// countBuckets переводит счетчики значений в группы от самых многочисленных
+func countBuckets(counts map[string]int64) []models.CountBucket {
+	buckets := make([]models.CountBucket, 0, len(counts))
+	for value, count := range counts {
+		buckets = append(buckets, models.CountBucket{Value: value, Count: count})
+	}
+	slices.SortFunc(buckets, func(a, b models.CountBucket) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Value, b.Value))
+	})
+	return buckets
+}